@@ -3,6 +3,10 @@ package dao
 import (
 	"context"
 	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	database "QA-System/internal/pkg/database/mongodb"
 
@@ -15,10 +19,42 @@ import (
 
 // Answer 各问题答卷模型
 type Answer struct {
-	QuestionID int    `json:"question_id" bson:"questionid"` // 问题ID
-	SerialNum  int    `json:"serial_num" bson:"serialnum"`   // 问题序号
-	Subject    string `json:"subject" bson:"subject"`        // 问题标题
-	Content    string `json:"content" bson:"content"`        // 答案内容
+	QuestionID int     `json:"question_id" bson:"questionid"` // 问题ID
+	SerialNum  int     `json:"serial_num" bson:"serialnum"`   // 问题序号
+	Subject    string  `json:"subject" bson:"subject"`        // 问题标题
+	Content    string  `json:"content" bson:"content"`        // 答案内容
+	Score      float64 `json:"score" bson:"score"`            // 测验得分
+}
+
+// MatrixAnswer 矩阵题单行答案
+// 矩阵题答案在 Answer.Content 中编码为 "行序号:列序号"，多行之间用 "┋" 分隔
+type MatrixAnswer struct {
+	Row    int `json:"row"`    // 行序号
+	Column int `json:"column"` // 列序号
+}
+
+// ParseMatrixAnswer 解析矩阵题答案
+func ParseMatrixAnswer(content string) ([]MatrixAnswer, error) {
+	matrixAnswers := make([]MatrixAnswer, 0)
+	if content == "" {
+		return matrixAnswers, nil
+	}
+	for _, item := range strings.Split(content, "┋") {
+		row, column, ok := strings.Cut(item, ":")
+		if !ok {
+			return nil, errors.New("矩阵题答案格式错误")
+		}
+		r, err := strconv.Atoi(row)
+		if err != nil {
+			return nil, errors.New("矩阵题行序号格式错误")
+		}
+		col, err := strconv.Atoi(column)
+		if err != nil {
+			return nil, errors.New("矩阵题列序号格式错误")
+		}
+		matrixAnswers = append(matrixAnswers, MatrixAnswer{Row: r, Column: col})
+	}
+	return matrixAnswers, nil
 }
 
 // AnswerSheet mongodb答卷表模型
@@ -28,6 +64,8 @@ type AnswerSheet struct {
 	Time     string             `json:"time" bson:"time"`          // 答卷时间
 	Unique   bool               `json:"unique" bson:"unique"`      // 是否唯一
 	Answers  []Answer           `json:"answers" bson:"answers"`    // 答案列表
+	Score    float64            `json:"score" bson:"score"`        // 测验总分
+	Version  int                `json:"version" bson:"version"`    // 作答时的问卷版本
 }
 
 // QuestionAnswers 问题答案模型
@@ -42,10 +80,12 @@ type AnswersResonse struct {
 	QuestionAnswers []QuestionAnswers    `json:"question_answers"`
 	AnswerIDs       []primitive.ObjectID `json:"answer_ids"`
 	Time            []string             `json:"time"`
+	Scores          []float64            `json:"scores,omitempty"` // 测验得分
 }
 
 // SaveAnswerSheet 将答卷直接保存到 MongoDB 集合中
-func (d *Dao) SaveAnswerSheet(ctx context.Context, answerSheet AnswerSheet, qids []int) error {
+// 返回因与新答卷的唯一问题答案重复而不再唯一的答卷
+func (d *Dao) SaveAnswerSheet(ctx context.Context, answerSheet AnswerSheet, qids []int) ([]AnswerSheet, error) {
 	// 构建查询条件
 	matchConditions := make([]bson.M, 0) // 初始化为空切片
 	for _, answer := range answerSheet.Answers {
@@ -65,9 +105,9 @@ func (d *Dao) SaveAnswerSheet(ctx context.Context, answerSheet AnswerSheet, qids
 		// 没有符合条件的记录，直接插入新记录
 		_, err := d.mongo.Collection(database.QA).InsertOne(ctx, answerSheet)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		return nil
+		return nil, nil
 	}
 
 	filter := bson.M{
@@ -82,36 +122,32 @@ func (d *Dao) SaveAnswerSheet(ctx context.Context, answerSheet AnswerSheet, qids
 			// 没有找到符合条件的记录，直接插入新记录
 			_, err := d.mongo.Collection(database.QA).InsertOne(ctx, answerSheet)
 			if err != nil {
-				return err
+				return nil, err
 			}
-			return nil
+			return nil, nil
 		}
-		return err
+		return nil, err
 	}
 
 	// 更新找到的记录，将unique设为false
 	update := bson.M{
 		"$set": bson.M{"unique": false},
 	}
-	_, err = d.mongo.Collection(database.QA).UpdateOne(ctx, filter, update)
+	_, err = d.mongo.Collection(database.QA).UpdateOne(ctx, bson.M{"_id": result.AnswerID}, update)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// 新增一条记录
-	newAnswerSheet := AnswerSheet{
-		SurveyID: answerSheet.SurveyID,
-		Time:     answerSheet.Time,
-		Unique:   true,
-		Answers:  answerSheet.Answers,
-	}
+	newAnswerSheet := answerSheet
+	newAnswerSheet.Unique = true
 
 	_, err = d.mongo.Collection(database.QA).InsertOne(ctx, newAnswerSheet)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	return []AnswerSheet{result}, nil
 }
 
 func contains(arr []int, item int) bool {
@@ -190,6 +226,106 @@ func (d *Dao) GetAnswerSheetBySurveyID(
 	return answerSheets, &total, nil
 }
 
+// AnswerPartCount 答案按 "┋" 拆分后各部分的数量
+type AnswerPartCount struct {
+	QuestionID int    `bson:"questionid"` // 问题ID
+	Content    string `bson:"content"`    // 拆分后的答案内容
+	Position   int    `bson:"position"`   // 在答案中的位置，从0开始
+	Count      int    `bson:"count"`      // 数量
+}
+
+// CountAnswerParts 使用聚合管道统计问卷中各题目答案拆分后各部分在各位置的数量
+// 选择题的选项、矩阵题的行列、量表题的数值和排序题的名次均可由该结果计算
+func (d *Dao) CountAnswerParts(ctx context.Context, surveyID int64, questionIDs []int) ([]AnswerPartCount, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"surveyid": surveyID, "unique": true}}},
+		{{Key: "$unwind", Value: "$answers"}},
+		{{Key: "$match", Value: bson.M{
+			"answers.questionid": bson.M{"$in": questionIDs},
+			"answers.content":    bson.M{"$ne": ""},
+		}}},
+		{{Key: "$project", Value: bson.M{
+			"questionid": "$answers.questionid",
+			"parts":      bson.M{"$split": bson.A{"$answers.content", "┋"}},
+		}}},
+		{{Key: "$unwind", Value: bson.M{"path": "$parts", "includeArrayIndex": "position"}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   bson.M{"questionid": "$questionid", "content": "$parts", "position": "$position"},
+			"count": bson.M{"$sum": 1},
+		}}},
+		{{Key: "$project", Value: bson.M{
+			"_id":        0,
+			"questionid": "$_id.questionid",
+			"content":    "$_id.content",
+			"position":   "$_id.position",
+			"count":      1,
+		}}},
+	}
+	cur, err := d.mongo.Collection(database.QA).Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	counts := make([]AnswerPartCount, 0)
+	if err := cur.All(ctx, &counts); err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+// CountAnswerSheetsBySurveyID 统计问卷的有效答卷数量
+func (d *Dao) CountAnswerSheetsBySurveyID(ctx context.Context, surveyID int64) (int64, error) {
+	filter := bson.M{"surveyid": surveyID, "unique": true}
+	return d.mongo.Collection(database.QA).CountDocuments(ctx, filter)
+}
+
+// AnswerFilter 按题目答案筛选答卷，QuestionIDs 为同一题目在各版本中的ID
+type AnswerFilter struct {
+	QuestionIDs []int
+	Content     string // 答案内容，多选题匹配其中任一选项
+}
+
+// TimeBucket 按时间分段的答卷数量
+type TimeBucket struct {
+	Time  string `bson:"_id"`   // 时间段，按小时为 "2006-01-02 15"，按天为 "2006-01-02"
+	Count int    `bson:"count"` // 答卷数量
+}
+
+// CountAnswerSheetsByTime 使用聚合管道按小时或天统计问卷的答卷数量，按时间升序返回
+func (d *Dao) CountAnswerSheetsByTime(
+	ctx context.Context, surveyID int64, interval string, filter *AnswerFilter) ([]TimeBucket, error) {
+	match := bson.M{"surveyid": surveyID, "unique": true}
+	if filter != nil {
+		// 多选题答案用 "┋" 连接，按完整选项匹配
+		pattern := "(^|┋)" + regexp.QuoteMeta(filter.Content) + "(┋|$)"
+		match["answers"] = bson.M{"$elemMatch": bson.M{
+			"questionid": bson.M{"$in": filter.QuestionIDs},
+			"content":    bson.M{"$regex": pattern},
+		}}
+	}
+	// 答卷时间格式为 "2006-01-02 15:04:05"，截取前缀即为所在的时间段
+	length := len(time.DateOnly)
+	if interval == "hour" {
+		length = len("2006-01-02 15")
+	}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   bson.M{"$substrBytes": bson.A{"$time", 0, length}},
+			"count": bson.M{"$sum": 1},
+		}}},
+		{{Key: "$sort", Value: bson.M{"_id": 1}}},
+	}
+	cur, err := d.mongo.Collection(database.QA).Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	buckets := make([]TimeBucket, 0)
+	if err := cur.All(ctx, &buckets); err != nil {
+		return nil, err
+	}
+	return buckets, nil
+}
+
 // DeleteAnswerSheetBySurveyID 根据问卷ID删除答卷
 func (d *Dao) DeleteAnswerSheetBySurveyID(ctx context.Context, surveyID int64) error {
 	filter := bson.M{"surveyid": surveyID}
@@ -207,9 +343,99 @@ func (d *Dao) DeleteAnswerSheetByAnswerID(ctx context.Context, answerID primitiv
 }
 
 // GetAnswerSheetByAnswerID 根据答卷ID获取答卷
-func (d *Dao) GetAnswerSheetByAnswerID(ctx context.Context, answerID primitive.ObjectID) error {
+func (d *Dao) GetAnswerSheetByAnswerID(ctx context.Context, answerID primitive.ObjectID) (*AnswerSheet, error) {
 	var answerSheet AnswerSheet
 	filter := bson.M{"_id": answerID}
 	err := d.mongo.Collection(database.QA).FindOne(ctx, filter).Decode(&answerSheet)
-	return err
+	if err != nil {
+		return nil, err
+	}
+	return &answerSheet, nil
+}
+
+// UpdateAnswerSheet 替换答卷内容，并保持唯一问题的答卷标记一致
+// oldAnswers 为修改前的答案，qids 为需要保证唯一的问题ID
+// 返回因此不再唯一的其他答卷和恢复为唯一的其他答卷
+func (d *Dao) UpdateAnswerSheet(ctx context.Context, answerSheet AnswerSheet, oldAnswers []Answer,
+	qids []int) ([]AnswerSheet, []AnswerSheet, error) {
+	collection := d.mongo.Collection(database.QA)
+	// 与新答案重复的其他答卷不再唯一
+	demoted := make([]AnswerSheet, 0)
+	for _, answer := range answerSheet.Answers {
+		if !contains(qids, answer.QuestionID) {
+			continue
+		}
+		filter := bson.M{
+			"_id":    bson.M{"$ne": answerSheet.AnswerID},
+			"unique": true,
+			"answers": bson.M{"$elemMatch": bson.M{
+				"questionid": answer.QuestionID,
+				"content":    answer.Content,
+			}},
+		}
+		cur, err := collection.Find(ctx, filter)
+		if err != nil {
+			return nil, nil, err
+		}
+		var sheets []AnswerSheet
+		if err := cur.All(ctx, &sheets); err != nil {
+			return nil, nil, err
+		}
+		if len(sheets) == 0 {
+			continue
+		}
+		ids := make([]primitive.ObjectID, 0, len(sheets))
+		for _, sheet := range sheets {
+			ids = append(ids, sheet.AnswerID)
+		}
+		_, err = collection.UpdateMany(ctx, bson.M{"_id": bson.M{"$in": ids}}, bson.M{"$set": bson.M{"unique": false}})
+		if err != nil {
+			return nil, nil, err
+		}
+		demoted = append(demoted, sheets...)
+	}
+
+	update := bson.M{"$set": bson.M{
+		"time":    answerSheet.Time,
+		"unique":  true,
+		"answers": answerSheet.Answers,
+		"score":   answerSheet.Score,
+	}}
+	if _, err := collection.UpdateOne(ctx, bson.M{"_id": answerSheet.AnswerID}, update); err != nil {
+		return nil, nil, err
+	}
+
+	// 修改前的答案不再被占用时，恢复最近一份相同答案的答卷为唯一
+	promoted := make([]AnswerSheet, 0)
+	for _, answer := range oldAnswers {
+		if !contains(qids, answer.QuestionID) {
+			continue
+		}
+		match := bson.M{"$elemMatch": bson.M{
+			"questionid": answer.QuestionID,
+			"content":    answer.Content,
+		}}
+		count, err := collection.CountDocuments(ctx,
+			bson.M{"surveyid": answerSheet.SurveyID, "unique": true, "answers": match})
+		if err != nil {
+			return nil, nil, err
+		}
+		if count > 0 {
+			continue
+		}
+		var sheet AnswerSheet
+		opts := options.FindOneAndUpdate().SetSort(bson.D{{Key: "time", Value: -1}})
+		err = collection.FindOneAndUpdate(ctx,
+			bson.M{"surveyid": answerSheet.SurveyID, "unique": false, "answers": match},
+			bson.M{"$set": bson.M{"unique": true}}, opts).Decode(&sheet)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		sheet.Unique = true
+		promoted = append(promoted, sheet)
+	}
+	return demoted, promoted, nil
 }
@@ -28,15 +28,31 @@ func New(orm *gorm.DB, mongodb *mongo.Database) Daos {
 	return dao
 }
 
+// Transaction 在 MySQL 事务中执行 fn，fn 返回错误时回滚
+// fn 应使用传入的 Daos 访问 MySQL，MongoDB 和 Redis 的操作不在事务内
+func (d *Dao) Transaction(ctx context.Context, fn func(tx Daos) error) error {
+	return d.orm.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&Dao{orm: tx, mongo: d.mongo})
+	})
+}
+
 // Daos 数据访问对象接口
 type Daos interface {
-	SaveAnswerSheet(ctx context.Context, answerSheet AnswerSheet, qids []int) error
+	Transaction(ctx context.Context, fn func(tx Daos) error) error
+
+	SaveAnswerSheet(ctx context.Context, answerSheet AnswerSheet, qids []int) ([]AnswerSheet, error)
 	GetAnswerSheetBySurveyID(
 		ctx context.Context, surveyID int64, pageNum int, pageSize int, text string, unique bool) (
 		[]AnswerSheet, *int64, error)
+	CountAnswerParts(ctx context.Context, surveyID int64, questionIDs []int) ([]AnswerPartCount, error)
+	CountAnswerSheetsBySurveyID(ctx context.Context, surveyID int64) (int64, error)
+	CountAnswerSheetsByTime(
+		ctx context.Context, surveyID int64, interval string, filter *AnswerFilter) ([]TimeBucket, error)
 	DeleteAnswerSheetBySurveyID(ctx context.Context, surveyID int64) error
 	DeleteAnswerSheetByAnswerID(ctx context.Context, answerID primitive.ObjectID) error
-	GetAnswerSheetByAnswerID(ctx context.Context, answerID primitive.ObjectID) error
+	GetAnswerSheetByAnswerID(ctx context.Context, answerID primitive.ObjectID) (*AnswerSheet, error)
+	UpdateAnswerSheet(ctx context.Context, answerSheet AnswerSheet, oldAnswers []Answer,
+		qids []int) ([]AnswerSheet, []AnswerSheet, error)
 
 	CreateManage(ctx context.Context, id int, surveyID int64) error
 	DeleteManage(ctx context.Context, id int, surveyID int64) error
@@ -53,23 +69,62 @@ type Daos interface {
 
 	CreateQuestion(ctx context.Context, question model.Question) (model.Question, error)
 	GetQuestionsBySurveyID(ctx context.Context, surveyID int64) ([]model.Question, error)
+	GetAllQuestionsBySurveyID(ctx context.Context, surveyID int64) ([]model.Question, error)
 	GetQuestionByID(ctx context.Context, questionID int) (*model.Question, error)
 	DeleteQuestion(ctx context.Context, questionID int) error
 	DeleteQuestionBySurveyID(ctx context.Context, surveyID int64) error
 	CreateType(ctx context.Context, name string, value string) error
 	GetType(ctx context.Context, name string) (string, error)
 
+	CreateRule(ctx context.Context, rule model.Rule) error
+	GetRulesBySurveyID(ctx context.Context, surveyID int64) ([]model.Rule, error)
+	DeleteRulesBySurveyID(ctx context.Context, surveyID int64) error
+
+	CreateSection(ctx context.Context, section model.Section) error
+	GetSectionsBySurveyID(ctx context.Context, surveyID int64) ([]model.Section, error)
+	DeleteSectionsBySurveyID(ctx context.Context, surveyID int64) error
+
 	SaveRecordSheet(ctx context.Context, answerSheet RecordSheet, sid int64) error
 	DeleteRecordSheets(ctx context.Context, surveyID int64) error
+	GetRecordSheetByAnswerID(ctx context.Context, surveyID int64, answerID primitive.ObjectID) (*RecordSheet, error)
+	DeleteRecordSheetByAnswerID(ctx context.Context, surveyID int64, answerID primitive.ObjectID) error
+	GetRecordSheetByStudentID(ctx context.Context, surveyID int64, studentID string) (*RecordSheet, error)
+	GetRecordSheetsBySurveyID(ctx context.Context, surveyID int64) ([]RecordSheet, error)
+	CountRecordSheets(ctx context.Context, surveyID int64, field string, value string) (int64, error)
+
+	CreateQuota(ctx context.Context, quota model.Quota) error
+	GetQuotasBySurveyID(ctx context.Context, surveyID int64) ([]model.Quota, error)
+	DeleteQuotasBySurveyID(ctx context.Context, surveyID int64) error
+
+	CreateTemplate(ctx context.Context, template model.Template) error
+	GetTemplateByID(ctx context.Context, id int) (*model.Template, error)
+	GetTemplatesByUserID(ctx context.Context, uid int) ([]model.Template, error)
+	DeleteTemplate(ctx context.Context, id int) error
+
+	SaveRecurrence(ctx context.Context, recurrence *model.Recurrence) error
+	GetRecurrenceBySurveyID(ctx context.Context, surveyID int64) (*model.Recurrence, error)
+	GetDueRecurrences(ctx context.Context, now time.Time) ([]model.Recurrence, error)
+	AdvanceRecurrence(ctx context.Context, id int, current, next time.Time) (bool, error)
+	DeleteRecurrenceBySurveyID(ctx context.Context, surveyID int64) error
+
+	CreateTranslations(ctx context.Context, translations []model.Translation) error
+	GetTranslationsBySurveyID(ctx context.Context, surveyID int64) ([]model.Translation, error)
+	GetTranslationsByLocale(ctx context.Context, surveyID int64, locale string) ([]model.Translation, error)
+	GetTranslationLocales(ctx context.Context, surveyID int64) ([]string, error)
+	DeleteTranslationsByLocale(ctx context.Context, surveyID int64, locale string) error
+	DeleteTranslationsBySurveyID(ctx context.Context, surveyID int64) error
 
 	CreateSurvey(ctx context.Context, survey model.Survey) (model.Survey, error)
 	UpdateSurveyStatus(ctx context.Context, surveyID int64, status int) error
-	UpdateSurvey(ctx context.Context, id int64, surveyType, limit uint,
-		sumLimit uint, verify bool, undergradOnly bool, desc string, title string, deadline, startTime time.Time,
-		needNotify bool) error
+	UpdateSurveyVersion(ctx context.Context, surveyID int64, version int) error
+	UpdateSurvey(ctx context.Context, survey *model.Survey) error
 	GetSurveyByUserID(ctx context.Context, userId int) ([]model.Survey, error)
 	GetSurveyByID(ctx context.Context, surveyID int64) (*model.Survey, error)
 	GetAllSurvey(ctx context.Context) ([]model.Survey, error)
+	GetSurveysToPublish(ctx context.Context, now time.Time) ([]model.Survey, error)
+	GetSurveysToClose(ctx context.Context, now time.Time) ([]model.Survey, error)
+	GetSurveysBySeriesID(ctx context.Context, seriesID int) ([]model.Survey, error)
+	UpdateSurveySeries(ctx context.Context, surveyID int64, seriesID int) error
 	IncreaseSurveyNum(ctx context.Context, sid int64) error
 	DeleteSurvey(ctx context.Context, surveyID int64) error
 
@@ -12,13 +12,19 @@ import (
 
 // BaseConfig 基本配置模型
 type BaseConfig struct {
-	StartTime     string `json:"start_time" binding:"datetime=2006-01-02T15:04:05+08:00"`
-	EndTime       string `json:"end_time" binding:"datetime=2006-01-02T15:04:05+08:00"`
-	DailyLimit    uint   `json:"day_limit"`      // 问卷每日填写限制
-	SumLimit      uint   `json:"sum_limit"`      // 问卷总填写次数限制
-	Verify        bool   `json:"verify"`         // 问卷是否需要统一验证
-	UndergradOnly bool   `json:"undergrad_only"` // 是否只限制本科生作答
-	NeedNotify    bool   `json:"need_notify"`    // 问卷在收到回复时是否需要提醒
+	StartTime        string  `json:"start_time" binding:"datetime=2006-01-02T15:04:05+08:00"`
+	EndTime          string  `json:"end_time" binding:"datetime=2006-01-02T15:04:05+08:00"`
+	DailyLimit       uint    `json:"day_limit"`         // 问卷每日填写限制
+	SumLimit         uint    `json:"sum_limit"`         // 问卷总填写次数限制
+	Verify           bool    `json:"verify"`            // 问卷是否需要统一验证
+	UndergradOnly    bool    `json:"undergrad_only"`    // 是否只限制本科生作答
+	NeedNotify       bool    `json:"need_notify"`       // 问卷在收到回复时是否需要提醒
+	ShuffleQuestions bool    `json:"shuffle_questions"` // 是否打乱题目顺序
+	ShowScore        bool    `json:"show_score"`        // 测验提交后是否返回得分
+	AutoPublish      bool    `json:"auto_publish"`      // 是否在开始时间自动发布
+	HideLiveResults  bool    `json:"hide_live_results"` // 投票截止前是否隐藏实时结果
+	ResultVisibility uint    `json:"result_visibility"` // 投票结果公开范围 0:总是 1:不公开 2:提交后 3:截止后 4:仅统一验证用户
+	Quotas           []Quota `json:"quotas"`            // 按答题者属性的配额
 }
 
 // QuestionConfig 问题配置模型
@@ -26,28 +32,41 @@ type QuestionConfig struct {
 	Desc         string         `json:"desc" `
 	Title        string         `json:"title"`
 	QuestionList []QuestionList `json:"question_list"`
+	Logic        []Rule         `json:"logic"`    // 题目显示逻辑
+	Sections     []Section      `json:"sections"` // 问卷分页
 }
 
 // QuestionList 问题列表模型
 type QuestionList struct {
+	Key             string          `json:"key"`          // 题目标识 修改问卷时保留以关联各版本的答卷 为空时自动生成
 	SerialNum       int             `json:"serial_num"`   // 题目序号
+	SectionNum      int             `json:"section_num"`  // 所属分页序号 0为不分页
 	Subject         string          `json:"subject"`      // 问题
 	Description     string          `json:"description"`  // 问题描述
 	Img             string          `json:"img"`          // 图片
 	QuestionSetting QuestionSetting `json:"ques_setting"` // 问题设置
-	Options         []Option        `json:"options"`      // 选项
+	Options         []Option        `json:"options"`      // 选项，矩阵题为行
+	Columns         []Option        `json:"columns"`      // 矩阵题的列
 }
 
 // QuestionSetting 问题设置模型
 type QuestionSetting struct {
-	Required      bool     `json:"required"`                                           // 是否必填
-	Unique        bool     `json:"unique"`                                             // 是否唯一
-	OtherOption   bool     `json:"other_option"`                                       // 是否有其他选项
-	QuestionType  int      `json:"question_type" binding:"required,oneof=1 2 3 4 5 6"` // 问题类型 1单选2多选3填空4简答5图片6文件
-	Reg           string   `json:"reg"`                                                // 正则表达式
-	Options       []Option `json:"options"`                                            // 选项
-	MaximumOption uint     `json:"maximum_option"`                                     // 多选最多选项数 0为不限制
-	MinimumOption uint     `json:"minimum_option"`                                     // 多选最少选项数 0为不限制
+	Required       bool     `json:"required"`                                                 // 是否必填
+	Unique         bool     `json:"unique"`                                                   // 是否唯一
+	OtherOption    bool     `json:"other_option"`                                             // 是否有其他选项
+	QuestionType   int      `json:"question_type" binding:"required,oneof=1 2 3 4 5 6 7 8 9"` // 问题类型 1单选2多选3填空4简答5图片6文件7矩阵8量表9排序
+	Reg            string   `json:"reg"`                                                      // 正则表达式
+	ShuffleOptions bool     `json:"shuffle_options"`                                          // 是否打乱选项顺序
+	Options        []Option `json:"options"`                                                  // 选项
+	MaximumOption  uint     `json:"maximum_option"`                                           // 多选最多选项数 0为不限制
+	MinimumOption  uint     `json:"minimum_option"`                                           // 多选最少选项数 0为不限制
+	InputType      int      `json:"input_type"`                                               // 填空题输入类型 0不限1日期2日期时间3整数4小数5邮箱6手机号7学号
+	ScaleType      int      `json:"scale_type"`                                               // 量表类型 1评分2NPS3滑块
+	MinValue       float64  `json:"min_value"`                                                // 最小值
+	MaxValue       float64  `json:"max_value"`                                                // 最大值
+	Step           float64  `json:"step"`                                                     // 步长
+	Score          float64  `json:"score"`                                                    // 测验题目分值
+	AnswerKey      string   `json:"answer_key"`                                               // 填空题标准答案，多个用┋分隔
 }
 
 // QuestionsList 问题列表模型
@@ -62,7 +81,7 @@ func (d *Dao) CreateQuestion(ctx context.Context, question model.Question) (mode
 	return question, err
 }
 
-// GetQuestionsBySurveyID 根据问卷ID获取问题列表
+// GetQuestionsBySurveyID 根据问卷ID获取当前版本的问题列表
 func (d *Dao) GetQuestionsBySurveyID(ctx context.Context, surveyID int64) ([]model.Question, error) {
 	var questions []model.Question
 	cacheData, err := redis.RedisClient.Get(ctx, fmt.Sprintf("questions:sid:%d", surveyID)).Result()
@@ -72,7 +91,9 @@ func (d *Dao) GetQuestionsBySurveyID(ctx context.Context, surveyID int64) ([]mod
 			return questions, nil
 		}
 	}
-	err = d.orm.WithContext(ctx).Model(model.Question{}).Where("survey_id = ?", surveyID).Find(&questions).Error
+	version := d.orm.WithContext(ctx).Model(model.Survey{}).Select("version").Where("id = ?", surveyID)
+	err = d.orm.WithContext(ctx).Model(model.Question{}).Where("survey_id = ? AND version = (?)", surveyID, version).
+		Find(&questions).Error
 	if err != nil {
 		return nil, err
 	}
@@ -84,6 +105,13 @@ func (d *Dao) GetQuestionsBySurveyID(ctx context.Context, surveyID int64) ([]mod
 	return questions, err
 }
 
+// GetAllQuestionsBySurveyID 根据问卷ID获取包括历史版本在内的全部问题
+func (d *Dao) GetAllQuestionsBySurveyID(ctx context.Context, surveyID int64) ([]model.Question, error) {
+	var questions []model.Question
+	err := d.orm.WithContext(ctx).Model(model.Question{}).Where("survey_id = ?", surveyID).Find(&questions).Error
+	return questions, err
+}
+
 // GetQuestionByID 根据问题ID获取问题
 func (d *Dao) GetQuestionByID(ctx context.Context, questionID int) (*model.Question, error) {
 	var question model.Question
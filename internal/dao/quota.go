@@ -0,0 +1,56 @@
+package dao
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"QA-System/internal/model"
+	"QA-System/internal/pkg/redis"
+)
+
+// Quota 问卷配额模型
+type Quota struct {
+	Field string `json:"field"` // 配额字段 college学院gender性别user_type用户类型user_type_desc用户类型描述
+	Value string `json:"value"` // 配额字段取值
+	Limit int    `json:"limit"` // 配额上限
+}
+
+// CreateQuota 创建配额
+func (d *Dao) CreateQuota(ctx context.Context, quota model.Quota) error {
+	err := d.orm.WithContext(ctx).Create(&quota).Error
+	return err
+}
+
+// GetQuotasBySurveyID 根据问卷ID获取配额
+func (d *Dao) GetQuotasBySurveyID(ctx context.Context, surveyID int64) ([]model.Quota, error) {
+	var quotas []model.Quota
+	cachedData, err := redis.RedisClient.Get(ctx, fmt.Sprintf("quotas:sid:%d", surveyID)).Result()
+	if err == nil && cachedData != "" {
+		// 反序列化 JSON 为结构体
+		if err := json.Unmarshal([]byte(cachedData), &quotas); err == nil {
+			return quotas, nil
+		}
+	}
+	err = d.orm.WithContext(ctx).Model(model.Quota{}).Where("survey_id = ?", surveyID).Find(&quotas).Error
+	if err != nil {
+		return nil, err
+	}
+	// 序列化为 JSON 后存储到 Redis
+	jsonData, err := json.Marshal(quotas)
+	if err == nil {
+		redis.RedisClient.Set(ctx, fmt.Sprintf("quotas:sid:%d", surveyID), jsonData, 20*time.Minute)
+	}
+	return quotas, nil
+}
+
+// DeleteQuotasBySurveyID 根据问卷ID删除配额
+func (d *Dao) DeleteQuotasBySurveyID(ctx context.Context, surveyID int64) error {
+	err := redis.RedisClient.Del(ctx, fmt.Sprintf("quotas:sid:%d", surveyID)).Err()
+	if err != nil {
+		return err
+	}
+	err = d.orm.WithContext(ctx).Where("survey_id = ?", surveyID).Delete(&model.Quota{}).Error
+	return err
+}
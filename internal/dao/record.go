@@ -7,17 +7,20 @@ import (
 	database "QA-System/internal/pkg/database/mongodb"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // RecordSheet 记录表模型
 type RecordSheet struct {
-	College      string    `json:"college" bson:"college"`               // 学院
-	Name         string    `json:"name" bson:"name"`                     // 姓名
-	StudentID    string    `json:"student_id" bson:"student_id"`         // 学生ID
-	UserType     string    `json:"user_type" bson:"user_type"`           // 用户类型id
-	UserTypeDesc string    `json:"user_type_desc" bson:"user_type_desc"` // 用户类型 text
-	Gender       string    `json:"gender" bson:"gender"`                 // 性别
-	Time         time.Time `json:"time" bson:"time"`                     // 答卷时间
+	College      string             `json:"college" bson:"college"`               // 学院
+	Name         string             `json:"name" bson:"name"`                     // 姓名
+	StudentID    string             `json:"student_id" bson:"student_id"`         // 学生ID
+	UserType     string             `json:"user_type" bson:"user_type"`           // 用户类型id
+	UserTypeDesc string             `json:"user_type_desc" bson:"user_type_desc"` // 用户类型 text
+	Gender       string             `json:"gender" bson:"gender"`                 // 性别
+	Time         time.Time          `json:"time" bson:"time"`                     // 答卷时间
+	AnswerID     primitive.ObjectID `json:"answer_id" bson:"answer_id"`           // 答卷ID
 }
 
 // SaveRecordSheet 将记录直接保存到 MongoDB 集合中
@@ -26,6 +29,66 @@ func (d *Dao) SaveRecordSheet(ctx context.Context, answerSheet RecordSheet, sid
 	return err
 }
 
+// GetRecordSheetByStudentID 获取学生在问卷中最近一次的记录
+func (d *Dao) GetRecordSheetByStudentID(ctx context.Context, surveyID int64, studentID string) (*RecordSheet, error) {
+	var result struct {
+		Record RecordSheet `bson:"record"`
+	}
+	filter := bson.M{"survey_id": surveyID, "record.student_id": studentID}
+	opts := options.FindOne().SetSort(bson.D{{Key: "record.time", Value: -1}})
+	err := d.mongo.Collection(database.Record).FindOne(ctx, filter, opts).Decode(&result)
+	if err != nil {
+		return nil, err
+	}
+	return &result.Record, nil
+}
+
+// GetRecordSheetByAnswerID 获取答卷对应的记录
+func (d *Dao) GetRecordSheetByAnswerID(ctx context.Context, surveyID int64,
+	answerID primitive.ObjectID) (*RecordSheet, error) {
+	var result struct {
+		Record RecordSheet `bson:"record"`
+	}
+	filter := bson.M{"survey_id": surveyID, "record.answer_id": answerID}
+	err := d.mongo.Collection(database.Record).FindOne(ctx, filter).Decode(&result)
+	if err != nil {
+		return nil, err
+	}
+	return &result.Record, nil
+}
+
+// DeleteRecordSheetByAnswerID 删除答卷对应的记录
+func (d *Dao) DeleteRecordSheetByAnswerID(ctx context.Context, surveyID int64, answerID primitive.ObjectID) error {
+	filter := bson.M{"survey_id": surveyID, "record.answer_id": answerID}
+	_, err := d.mongo.Collection(database.Record).DeleteOne(ctx, filter)
+	return err
+}
+
+// GetRecordSheetsBySurveyID 获取问卷的全部记录
+func (d *Dao) GetRecordSheetsBySurveyID(ctx context.Context, surveyID int64) ([]RecordSheet, error) {
+	cursor, err := d.mongo.Collection(database.Record).Find(ctx, bson.M{"survey_id": surveyID})
+	if err != nil {
+		return nil, err
+	}
+	var results []struct {
+		Record RecordSheet `bson:"record"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	records := make([]RecordSheet, 0, len(results))
+	for _, result := range results {
+		records = append(records, result.Record)
+	}
+	return records, nil
+}
+
+// CountRecordSheets 统计问卷中指定字段取值的记录数量
+func (d *Dao) CountRecordSheets(ctx context.Context, surveyID int64, field string, value string) (int64, error) {
+	filter := bson.M{"survey_id": surveyID, "record." + field: value}
+	return d.mongo.Collection(database.Record).CountDocuments(ctx, filter)
+}
+
 // DeleteRecordSheets 删除记录表
 func (d *Dao) DeleteRecordSheets(ctx context.Context, surveyID int64) error {
 	_, err := d.mongo.Collection(database.Record).DeleteMany(ctx, bson.M{"survey_id": surveyID})
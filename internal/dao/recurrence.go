@@ -0,0 +1,41 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"QA-System/internal/model"
+)
+
+// SaveRecurrence 创建或更新周期问卷规则
+func (d *Dao) SaveRecurrence(ctx context.Context, recurrence *model.Recurrence) error {
+	err := d.orm.WithContext(ctx).Save(recurrence).Error
+	return err
+}
+
+// GetRecurrenceBySurveyID 根据问卷ID获取周期问卷规则
+func (d *Dao) GetRecurrenceBySurveyID(ctx context.Context, surveyID int64) (*model.Recurrence, error) {
+	var recurrence model.Recurrence
+	err := d.orm.WithContext(ctx).Where("survey_id = ?", surveyID).First(&recurrence).Error
+	return &recurrence, err
+}
+
+// GetDueRecurrences 获取已到下一期开始时间的周期问卷规则
+func (d *Dao) GetDueRecurrences(ctx context.Context, now time.Time) ([]model.Recurrence, error) {
+	var recurrences []model.Recurrence
+	err := d.orm.WithContext(ctx).Where("enabled = ? AND next_run_at <= ?", true, now).Find(&recurrences).Error
+	return recurrences, err
+}
+
+// AdvanceRecurrence 仅当下一期开始时间仍为 current 时将其更新为 next，返回是否更新成功
+func (d *Dao) AdvanceRecurrence(ctx context.Context, id int, current, next time.Time) (bool, error) {
+	result := d.orm.WithContext(ctx).Model(&model.Recurrence{}).
+		Where("id = ? AND next_run_at = ?", id, current).Update("next_run_at", next)
+	return result.RowsAffected == 1, result.Error
+}
+
+// DeleteRecurrenceBySurveyID 根据问卷ID删除周期问卷规则
+func (d *Dao) DeleteRecurrenceBySurveyID(ctx context.Context, surveyID int64) error {
+	err := d.orm.WithContext(ctx).Where("survey_id = ?", surveyID).Delete(&model.Recurrence{}).Error
+	return err
+}
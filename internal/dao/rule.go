@@ -0,0 +1,61 @@
+package dao
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"QA-System/internal/model"
+	"QA-System/internal/pkg/redis"
+)
+
+// Rule 题目显示逻辑模型
+type Rule struct {
+	SerialNum     int    `json:"serial_num"`     // 条件题目序号
+	MatchType     int    `json:"match_type"`     // 条件类型 1:选中选项 2:文本匹配
+	OptionSerial  int    `json:"option_serial"`  // 条件选项序号
+	Text          string `json:"text"`           // 条件匹配文本
+	Action        int    `json:"action"`         // 动作 1:显示 2:隐藏 3:跳转
+	TargetSerial  int    `json:"target_serial"`  // 目标题目序号
+	TargetSection int    `json:"target_section"` // 目标分页序号 不为0时作用于整个分页
+}
+
+// CreateRule 创建显示逻辑
+func (d *Dao) CreateRule(ctx context.Context, rule model.Rule) error {
+	err := d.orm.WithContext(ctx).Create(&rule).Error
+	return err
+}
+
+// GetRulesBySurveyID 根据问卷ID获取显示逻辑
+func (d *Dao) GetRulesBySurveyID(ctx context.Context, surveyID int64) ([]model.Rule, error) {
+	var rules []model.Rule
+	cachedData, err := redis.RedisClient.Get(ctx, fmt.Sprintf("rules:sid:%d", surveyID)).Result()
+	if err == nil && cachedData != "" {
+		// 反序列化 JSON 为结构体
+		if err := json.Unmarshal([]byte(cachedData), &rules); err == nil {
+			return rules, nil
+		}
+	}
+	err = d.orm.WithContext(ctx).Model(model.Rule{}).Where("survey_id = ?", surveyID).
+		Order("serial_num").Find(&rules).Error
+	if err != nil {
+		return nil, err
+	}
+	// 序列化为 JSON 后存储到 Redis
+	jsonData, err := json.Marshal(rules)
+	if err == nil {
+		redis.RedisClient.Set(ctx, fmt.Sprintf("rules:sid:%d", surveyID), jsonData, 20*time.Minute)
+	}
+	return rules, nil
+}
+
+// DeleteRulesBySurveyID 根据问卷ID删除显示逻辑
+func (d *Dao) DeleteRulesBySurveyID(ctx context.Context, surveyID int64) error {
+	err := redis.RedisClient.Del(ctx, fmt.Sprintf("rules:sid:%d", surveyID)).Err()
+	if err != nil {
+		return err
+	}
+	err = d.orm.WithContext(ctx).Where("survey_id = ?", surveyID).Delete(&model.Rule{}).Error
+	return err
+}
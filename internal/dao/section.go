@@ -0,0 +1,58 @@
+package dao
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"QA-System/internal/model"
+	"QA-System/internal/pkg/redis"
+)
+
+// Section 问卷分页模型
+type Section struct {
+	SerialNum        int    `json:"serial_num"`        // 分页序号
+	Title            string `json:"title"`             // 分页标题
+	Desc             string `json:"desc"`              // 分页描述
+	ShuffleQuestions bool   `json:"shuffle_questions"` // 是否打乱分页内题目顺序
+}
+
+// CreateSection 创建分页
+func (d *Dao) CreateSection(ctx context.Context, section model.Section) error {
+	err := d.orm.WithContext(ctx).Create(&section).Error
+	return err
+}
+
+// GetSectionsBySurveyID 根据问卷ID获取分页
+func (d *Dao) GetSectionsBySurveyID(ctx context.Context, surveyID int64) ([]model.Section, error) {
+	var sections []model.Section
+	cachedData, err := redis.RedisClient.Get(ctx, fmt.Sprintf("sections:sid:%d", surveyID)).Result()
+	if err == nil && cachedData != "" {
+		// 反序列化 JSON 为结构体
+		if err := json.Unmarshal([]byte(cachedData), &sections); err == nil {
+			return sections, nil
+		}
+	}
+	err = d.orm.WithContext(ctx).Model(model.Section{}).Where("survey_id = ?", surveyID).
+		Order("serial_num").Find(&sections).Error
+	if err != nil {
+		return nil, err
+	}
+	// 序列化为 JSON 后存储到 Redis
+	jsonData, err := json.Marshal(sections)
+	if err == nil {
+		redis.RedisClient.Set(ctx, fmt.Sprintf("sections:sid:%d", surveyID), jsonData, 20*time.Minute)
+	}
+	return sections, nil
+}
+
+// DeleteSectionsBySurveyID 根据问卷ID删除分页
+func (d *Dao) DeleteSectionsBySurveyID(ctx context.Context, surveyID int64) error {
+	err := redis.RedisClient.Del(ctx, fmt.Sprintf("sections:sid:%d", surveyID)).Err()
+	if err != nil {
+		return err
+	}
+	err = d.orm.WithContext(ctx).Where("survey_id = ?", surveyID).Delete(&model.Section{}).Error
+	return err
+}
@@ -21,22 +21,38 @@ func (d *Dao) UpdateSurveyStatus(ctx context.Context, surveyID int64, status int
 	return err
 }
 
+// UpdateSurveyVersion 更新问卷版本
+func (d *Dao) UpdateSurveyVersion(ctx context.Context, surveyID int64, version int) error {
+	err := d.orm.WithContext(ctx).Model(&model.Survey{}).Where("id = ?", surveyID).Update("version", version).Error
+	return err
+}
+
 // UpdateSurvey 更新问卷
-func (d *Dao) UpdateSurvey(ctx context.Context, id int64, surveyType, limit uint,
-	sumLimit uint, verify bool, undergrad_only bool, desc string, title string, deadline, startTime time.Time,
-	needNotify bool) error {
-	err := d.orm.WithContext(ctx).Model(&model.Survey{}).Where("id = ?", id).
+func (d *Dao) UpdateSurvey(ctx context.Context, survey *model.Survey) error {
+	err := d.orm.WithContext(ctx).Model(&model.Survey{}).Where("id = ?", survey.ID).
 		Updates(model.Survey{
-			Deadline:      deadline,
-			DailyLimit:    limit,
-			SumLimit:      sumLimit,
-			Verify:        verify,
-			UndergradOnly: undergrad_only,
-			Desc:          desc,
-			Title:         title,
-			Type:          surveyType,
-			StartTime:     startTime,
-			NeedNotify:    needNotify,
+			Deadline:      survey.Deadline,
+			DailyLimit:    survey.DailyLimit,
+			SumLimit:      survey.SumLimit,
+			Verify:        survey.Verify,
+			UndergradOnly: survey.UndergradOnly,
+			Desc:          survey.Desc,
+			Title:         survey.Title,
+			Type:          survey.Type,
+			StartTime:     survey.StartTime,
+			NeedNotify:    survey.NeedNotify,
+		}).Error
+	if err != nil {
+		return err
+	}
+	// 零值不会被 Updates 更新，单独更新乱序、得分、自动发布和结果公开设置
+	err = d.orm.WithContext(ctx).Model(&model.Survey{}).Where("id = ?", survey.ID).
+		Updates(map[string]any{
+			"shuffle_questions": survey.ShuffleQuestions,
+			"show_score":        survey.ShowScore,
+			"auto_publish":      survey.AutoPublish,
+			"hide_live_results": survey.HideLiveResults,
+			"result_visibility": survey.ResultVisibility,
 		}).Error
 	return err
 }
@@ -62,6 +78,39 @@ func (d *Dao) GetAllSurvey(ctx context.Context) ([]model.Survey, error) {
 	return surveys, err
 }
 
+// GetSurveysToPublish 获取已到开始时间且开启自动发布的未发布问卷
+func (d *Dao) GetSurveysToPublish(ctx context.Context, now time.Time) ([]model.Survey, error) {
+	var surveys []model.Survey
+	err := d.orm.WithContext(ctx).Model(model.Survey{}).
+		Where("status = ? AND auto_publish = ? AND start_time <= ? AND deadline > ?", 1, true, now, now).
+		Find(&surveys).Error
+	return surveys, err
+}
+
+// GetSurveysToClose 获取已过截止时间但仍处于发布状态的问卷
+func (d *Dao) GetSurveysToClose(ctx context.Context, now time.Time) ([]model.Survey, error) {
+	var surveys []model.Survey
+	err := d.orm.WithContext(ctx).Model(model.Survey{}).
+		Where("status = ? AND deadline <= ? AND deadline > ?", 2, now, time.Time{}).
+		Find(&surveys).Error
+	return surveys, err
+}
+
+// GetSurveysBySeriesID 获取周期问卷规则生成的全部问卷
+func (d *Dao) GetSurveysBySeriesID(ctx context.Context, seriesID int) ([]model.Survey, error) {
+	var surveys []model.Survey
+	err := d.orm.WithContext(ctx).Model(model.Survey{}).Where("series_id = ?", seriesID).
+		Order("start_time").Find(&surveys).Error
+	return surveys, err
+}
+
+// UpdateSurveySeries 更新问卷所属的周期问卷规则
+func (d *Dao) UpdateSurveySeries(ctx context.Context, surveyID int64, seriesID int) error {
+	err := d.orm.WithContext(ctx).Model(&model.Survey{}).Where("id = ?", surveyID).
+		Update("series_id", seriesID).Error
+	return err
+}
+
 // IncreaseSurveyNum 增加问卷填写人数
 func (d *Dao) IncreaseSurveyNum(ctx context.Context, sid int64) error {
 	err := d.orm.WithContext(ctx).Model(&model.Survey{}).Where("id = ?", sid).
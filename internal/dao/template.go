@@ -0,0 +1,51 @@
+package dao
+
+import (
+	"context"
+
+	"QA-System/internal/model"
+)
+
+// SurveyDefinition 问卷定义模型，包含重建问卷所需的全部配置
+type SurveyDefinition struct {
+	SurveyType     uint           `json:"survey_type" binding:"oneof=0 1 2"` // 问卷类型 0:调研 1:投票 2:测验
+	BaseConfig     BaseConfig     `json:"base_config"`                       // 基本配置
+	QuestionConfig QuestionConfig `json:"ques_config"`                       // 问题设置
+	Translations   []Translation  `json:"translations"`                      // 各语言的翻译
+}
+
+// SurveyDocumentVersion 当前问卷定义文档的格式版本
+const SurveyDocumentVersion = 1
+
+// SurveyDocument 导入导出使用的问卷定义文档
+type SurveyDocument struct {
+	Version int `json:"version" binding:"required"` // 文档格式版本
+	SurveyDefinition
+}
+
+// CreateTemplate 创建问卷模板
+func (d *Dao) CreateTemplate(ctx context.Context, template model.Template) error {
+	err := d.orm.WithContext(ctx).Create(&template).Error
+	return err
+}
+
+// GetTemplateByID 根据ID获取问卷模板
+func (d *Dao) GetTemplateByID(ctx context.Context, id int) (*model.Template, error) {
+	var template model.Template
+	err := d.orm.WithContext(ctx).Where("id = ?", id).First(&template).Error
+	return &template, err
+}
+
+// GetTemplatesByUserID 获取用户可见的问卷模板，包括自己创建的模板和组织模板
+func (d *Dao) GetTemplatesByUserID(ctx context.Context, uid int) ([]model.Template, error) {
+	var templates []model.Template
+	err := d.orm.WithContext(ctx).Where("user_id = ? OR is_shared = ?", uid, true).
+		Order("created_at desc").Find(&templates).Error
+	return templates, err
+}
+
+// DeleteTemplate 删除问卷模板
+func (d *Dao) DeleteTemplate(ctx context.Context, id int) error {
+	err := d.orm.WithContext(ctx).Where("id = ?", id).Delete(&model.Template{}).Error
+	return err
+}
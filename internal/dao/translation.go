@@ -0,0 +1,76 @@
+package dao
+
+import (
+	"context"
+
+	"QA-System/internal/model"
+)
+
+// Translation 问卷某一语言的翻译
+type Translation struct {
+	Locale    string                `json:"locale" binding:"required,max=16"` // 语言，如 en
+	Title     string                `json:"title"`                            // 问卷标题
+	Desc      string                `json:"desc"`                             // 问卷描述
+	Questions []QuestionTranslation `json:"questions"`                        // 题目翻译
+}
+
+// QuestionTranslation 题目的翻译
+type QuestionTranslation struct {
+	Key         string              `json:"key" binding:"required"` // 题目标识
+	Subject     string              `json:"subject"`                // 题目
+	Description string              `json:"description"`            // 题目描述
+	Options     []OptionTranslation `json:"options"`                // 选项翻译
+	Columns     []OptionTranslation `json:"columns"`                // 矩阵题列的翻译
+}
+
+// OptionTranslation 选项的翻译
+type OptionTranslation struct {
+	SerialNum   int    `json:"serial_num" binding:"required"` // 选项序号
+	Content     string `json:"content"`                       // 选项内容
+	Description string `json:"description"`                   // 选项描述
+}
+
+// CreateTranslations 批量创建问卷翻译
+func (d *Dao) CreateTranslations(ctx context.Context, translations []model.Translation) error {
+	if len(translations) == 0 {
+		return nil
+	}
+	err := d.orm.WithContext(ctx).Create(&translations).Error
+	return err
+}
+
+// GetTranslationsBySurveyID 获取问卷的全部翻译
+func (d *Dao) GetTranslationsBySurveyID(ctx context.Context, surveyID int64) ([]model.Translation, error) {
+	var translations []model.Translation
+	err := d.orm.WithContext(ctx).Where("survey_id = ?", surveyID).Find(&translations).Error
+	return translations, err
+}
+
+// GetTranslationsByLocale 获取问卷某一语言的翻译
+func (d *Dao) GetTranslationsByLocale(ctx context.Context, surveyID int64, locale string) ([]model.Translation, error) {
+	var translations []model.Translation
+	err := d.orm.WithContext(ctx).Where("survey_id = ? AND locale = ?", surveyID, locale).
+		Find(&translations).Error
+	return translations, err
+}
+
+// GetTranslationLocales 获取问卷已有翻译的语言
+func (d *Dao) GetTranslationLocales(ctx context.Context, surveyID int64) ([]string, error) {
+	var locales []string
+	err := d.orm.WithContext(ctx).Model(&model.Translation{}).Where("survey_id = ?", surveyID).
+		Distinct().Pluck("locale", &locales).Error
+	return locales, err
+}
+
+// DeleteTranslationsByLocale 删除问卷某一语言的翻译
+func (d *Dao) DeleteTranslationsByLocale(ctx context.Context, surveyID int64, locale string) error {
+	err := d.orm.WithContext(ctx).Where("survey_id = ? AND locale = ?", surveyID, locale).
+		Delete(&model.Translation{}).Error
+	return err
+}
+
+// DeleteTranslationsBySurveyID 删除问卷的全部翻译
+func (d *Dao) DeleteTranslationsBySurveyID(ctx context.Context, surveyID int64) error {
+	err := d.orm.WithContext(ctx).Where("survey_id = ?", surveyID).Delete(&model.Translation{}).Error
+	return err
+}
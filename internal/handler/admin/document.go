@@ -0,0 +1,96 @@
+package admin
+
+import (
+	"errors"
+	"strconv"
+
+	"QA-System/internal/dao"
+	"QA-System/internal/pkg/code"
+	"QA-System/internal/pkg/utils"
+	"QA-System/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type exportSurveyData struct {
+	ID int64 `form:"id" binding:"required"`
+}
+
+// ExportSurvey 导出问卷定义文档
+func ExportSurvey(c *gin.Context) {
+	var data exportSurveyData
+	err := c.ShouldBindQuery(&data)
+	if err != nil {
+		code.AbortWithException(c, code.ParamError, err)
+		return
+	}
+	// 鉴权
+	user, err := service.GetUserSession(c)
+	if err != nil {
+		code.AbortWithException(c, code.NotLogin, err)
+		return
+	}
+	// 获取问卷
+	survey, err := service.GetSurveyByID(data.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		code.AbortWithException(c, code.SurveyNotExist, errors.New("问卷不存在"))
+		return
+	} else if err != nil {
+		code.AbortWithException(c, code.ServerError, err)
+		return
+	}
+	// 判断权限
+	if (user.AdminType != 2) && (user.AdminType != 1 || survey.UserID != user.ID) &&
+		!service.UserInManage(user.ID, survey.ID) {
+		code.AbortWithException(c, code.NoPermission, errors.New(user.Username+"无权限"))
+		return
+	}
+	document, err := service.ExportSurvey(survey)
+	if err != nil {
+		code.AbortWithException(c, code.ServerError, err)
+		return
+	}
+	utils.JsonSuccessResponse(c, document)
+}
+
+// ImportSurvey 导入问卷定义文档，按创建问卷的规则校验后创建未发布的问卷
+func ImportSurvey(c *gin.Context) {
+	var document dao.SurveyDocument
+	err := c.ShouldBindJSON(&document)
+	if err != nil {
+		code.AbortWithException(c, code.ParamError, err)
+		return
+	}
+	// 鉴权
+	user, err := service.GetUserSession(c)
+	if err != nil {
+		code.AbortWithException(c, code.NotLogin, err)
+		return
+	}
+	if document.Version > dao.SurveyDocumentVersion {
+		code.AbortWithException(c, code.ParamError,
+			errors.New("不支持的文档版本"+strconv.Itoa(document.Version)))
+		return
+	}
+	data := createSurveyData{
+		Status:         1,
+		SurveyType:     document.SurveyType,
+		BaseConfig:     document.BaseConfig,
+		QuestionConfig: document.QuestionConfig,
+	}
+	if !checkCreateSurvey(c, data) {
+		return
+	}
+	sid, err := service.ImportSurvey(user.ID, document)
+	if errors.Is(err, service.ErrImageURL) {
+		code.AbortWithException(c, code.ParamError, err)
+		return
+	} else if err != nil {
+		code.AbortWithException(c, code.ServerError, err)
+		return
+	}
+	utils.JsonSuccessResponse(c, gin.H{
+		"id": sid,
+	})
+}
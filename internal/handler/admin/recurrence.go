@@ -0,0 +1,164 @@
+package admin
+
+import (
+	"errors"
+
+	"QA-System/internal/model"
+	"QA-System/internal/pkg/code"
+	"QA-System/internal/pkg/utils"
+	"QA-System/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type setRecurrenceData struct {
+	SurveyID  int64 `json:"survey_id" binding:"required"`
+	Frequency int   `json:"frequency" binding:"required,oneof=1 2"` // 周期 1:每周 2:每月
+	Weekday   int   `json:"weekday" binding:"min=0,max=6"`          // 每周的星期几 0:周日
+	MonthDay  int   `json:"month_day"`                              // 每月的几号 1-28
+	Hour      int   `json:"hour" binding:"min=0,max=23"`
+	Minute    int   `json:"minute" binding:"min=0,max=59"`
+	Duration  int   `json:"duration" binding:"required,min=1"` // 每期开放时长，单位：小时
+	Enabled   bool  `json:"enabled"`
+}
+
+// SetRecurrence 设置问卷的周期规则，到期后以该问卷为模板生成新一期问卷
+func SetRecurrence(c *gin.Context) {
+	var data setRecurrenceData
+	err := c.ShouldBindJSON(&data)
+	if err != nil {
+		code.AbortWithException(c, code.ParamError, err)
+		return
+	}
+	user, err := service.GetUserSession(c)
+	if err != nil {
+		code.AbortWithException(c, code.NotLogin, err)
+		return
+	}
+	survey, ok := getPermittedSurvey(c, user, data.SurveyID)
+	if !ok {
+		return
+	}
+	// 每期开放时长不能超过周期，避免相邻两期重叠
+	if data.Frequency == 1 && data.Duration > 7*24 {
+		code.AbortWithException(c, code.RecurrenceError, errors.New("每周问卷的开放时长不能超过7天"))
+		return
+	}
+	if data.Frequency == 2 {
+		if data.MonthDay < 1 || data.MonthDay > 28 {
+			code.AbortWithException(c, code.RecurrenceError, errors.New("每月问卷的日期需在1到28号之间"))
+			return
+		}
+		if data.Duration > 28*24 {
+			code.AbortWithException(c, code.RecurrenceError, errors.New("每月问卷的开放时长不能超过28天"))
+			return
+		}
+	}
+	recurrence, err := service.SaveRecurrence(model.Recurrence{
+		SurveyID:  survey.ID,
+		UserID:    user.ID,
+		Frequency: data.Frequency,
+		Weekday:   data.Weekday,
+		MonthDay:  data.MonthDay,
+		Hour:      data.Hour,
+		Minute:    data.Minute,
+		Duration:  data.Duration,
+		Enabled:   data.Enabled,
+	})
+	if err != nil {
+		code.AbortWithException(c, code.ServerError, err)
+		return
+	}
+	utils.JsonSuccessResponse(c, recurrence)
+}
+
+type recurrenceSurveyData struct {
+	SurveyID int64 `form:"survey_id" binding:"required"`
+}
+
+// DeleteRecurrence 删除问卷的周期规则，已生成的问卷保留
+func DeleteRecurrence(c *gin.Context) {
+	var data recurrenceSurveyData
+	err := c.ShouldBindQuery(&data)
+	if err != nil {
+		code.AbortWithException(c, code.ParamError, err)
+		return
+	}
+	user, err := service.GetUserSession(c)
+	if err != nil {
+		code.AbortWithException(c, code.NotLogin, err)
+		return
+	}
+	survey, ok := getPermittedSurvey(c, user, data.SurveyID)
+	if !ok {
+		return
+	}
+	err = service.DeleteRecurrenceBySurveyID(survey.ID)
+	if err != nil {
+		code.AbortWithException(c, code.ServerError, err)
+		return
+	}
+	utils.JsonSuccessResponse(c, nil)
+}
+
+// GetSeries 获取周期问卷的规则、已生成的各期问卷以及汇总的选择题统计
+func GetSeries(c *gin.Context) {
+	var data recurrenceSurveyData
+	err := c.ShouldBindQuery(&data)
+	if err != nil {
+		code.AbortWithException(c, code.ParamError, err)
+		return
+	}
+	user, err := service.GetUserSession(c)
+	if err != nil {
+		code.AbortWithException(c, code.NotLogin, err)
+		return
+	}
+	survey, ok := getPermittedSurvey(c, user, data.SurveyID)
+	if !ok {
+		return
+	}
+	recurrence, err := service.GetRecurrenceBySurveyID(survey.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		code.AbortWithException(c, code.RecurrenceError, errors.New("问卷未设置周期规则"))
+		return
+	} else if err != nil {
+		code.AbortWithException(c, code.ServerError, err)
+		return
+	}
+	instances, err := service.GetSeriesInstances(recurrence)
+	if err != nil {
+		code.AbortWithException(c, code.ServerError, err)
+		return
+	}
+	statistics, total, err := service.GetSeriesStatistics(instances)
+	if err != nil {
+		code.AbortWithException(c, code.ServerError, err)
+		return
+	}
+	utils.JsonSuccessResponse(c, gin.H{
+		"recurrence": recurrence,
+		"instances":  instances,
+		"statistics": statistics,
+		"total":      total,
+	})
+}
+
+// getPermittedSurvey 获取问卷并判断当前管理员是否有权限操作，失败时直接返回错误
+func getPermittedSurvey(c *gin.Context, user *model.User, sid int64) (*model.Survey, bool) {
+	survey, err := service.GetSurveyByID(sid)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		code.AbortWithException(c, code.SurveyNotExist, errors.New("问卷不存在"))
+		return nil, false
+	} else if err != nil {
+		code.AbortWithException(c, code.ServerError, err)
+		return nil, false
+	}
+	if (user.AdminType != 2) && (user.AdminType != 1 || survey.UserID != user.ID) &&
+		!service.UserInManage(user.ID, survey.ID) {
+		code.AbortWithException(c, code.NoPermission, errors.New(user.Username+"无权限"))
+		return nil, false
+	}
+	return survey, true
+}
@@ -3,6 +3,8 @@ package admin
 import (
 	"errors"
 	"math"
+	"regexp"
+	"sort"
 	"strconv"
 	"time"
 
@@ -20,9 +22,9 @@ import (
 
 type createSurveyData struct {
 	Status         int                `json:"status" binding:"required,oneof=1 2"`
-	SurveyType     uint               `json:"survey_type"` // 问卷类型 0:调研 1:投票
-	BaseConfig     dao.BaseConfig     `json:"base_config"` // 基本配置
-	QuestionConfig dao.QuestionConfig `json:"ques_config"` // 问题设置
+	SurveyType     uint               `json:"survey_type" binding:"oneof=0 1 2"` // 问卷类型 0:调研 1:投票 2:测验
+	BaseConfig     dao.BaseConfig     `json:"base_config"`                       // 基本配置
+	QuestionConfig dao.QuestionConfig `json:"ques_config"`                       // 问题设置
 }
 
 // CreateSurvey 创建问卷
@@ -39,118 +41,178 @@ func CreateSurvey(c *gin.Context) {
 		code.AbortWithException(c, code.NotLogin, err)
 		return
 	}
-	// 解析时间转换为中国时间(UTC+8)
-	ddlTime, err := time.Parse(time.RFC3339, data.BaseConfig.EndTime)
+	if !checkCreateSurvey(c, data) {
+		return
+	}
+	// 创建问卷
+	_, err = service.CreateSurvey(user.ID, data.Status, data.SurveyType, data.BaseConfig, data.QuestionConfig)
 	if err != nil {
 		code.AbortWithException(c, code.ServerError, err)
 		return
 	}
+	utils.JsonSuccessResponse(c, nil)
+}
+
+// checkCreateSurvey 按创建问卷的规则检查问卷配置，检查不通过时已中止请求并返回 false
+func checkCreateSurvey(c *gin.Context, data createSurveyData) bool {
+	// 解析时间转换为中国时间(UTC+8)
+	ddlTime, err := time.Parse(time.RFC3339, data.BaseConfig.EndTime)
+	if err != nil {
+		code.AbortWithException(c, code.ParamError, err)
+		return false
+	}
 	startTime, err := time.Parse(time.RFC3339, data.BaseConfig.StartTime)
 	if err != nil {
-		code.AbortWithException(c, code.ServerError, err)
-		return
+		code.AbortWithException(c, code.ParamError, err)
+		return false
 	}
 	if startTime.After(ddlTime) {
 		code.AbortWithException(c, code.SurveyError, errors.New("开始时间晚于截止时间"))
-		return
+		return false
 	}
 	// 检查总投票次数大于日投票数
 	if data.BaseConfig.SumLimit != 0 && data.BaseConfig.DailyLimit != 0 &&
 		data.BaseConfig.SumLimit < data.BaseConfig.DailyLimit {
 		code.AbortWithException(c, code.SurveyError, errors.New("总投票次数小于单日投票次数"))
-		return
+		return false
 	}
 	// 检查问卷每个题目的序号没有重复且按照顺序递增
 	questionNumMap := make(map[int]bool)
 	for i, question := range data.QuestionConfig.QuestionList {
-		if data.SurveyType == 2 && (question.QuestionSetting.QuestionType != 2 && !question.QuestionSetting.Required) {
-			code.AbortWithException(c, code.SurveyError, errors.New("投票题目只能为多选必填题"))
-			return
-		}
 		if questionNumMap[question.SerialNum] {
 			code.AbortWithException(c, code.SurveyError, errors.New("题目序号"+strconv.Itoa(question.SerialNum)+"重复"))
-			return
+			return false
 		}
 		if i > 0 && question.SerialNum != data.QuestionConfig.QuestionList[i-1].SerialNum+1 {
 			code.AbortWithException(c, code.SurveyError, errors.New("题目序号不按顺序递增"))
-			return
+			return false
 		}
 		questionNumMap[question.SerialNum] = true
 		question.SerialNum = i + 1
 
 		// 检测多选题目的最多选项数和最少选项数
-		if ((question.QuestionSetting.QuestionType == 2 && data.SurveyType == 0) ||
+		if ((question.QuestionSetting.QuestionType == 2 && data.SurveyType != 1) ||
 			(question.QuestionSetting.QuestionType == 1 && data.SurveyType == 1)) &&
 			(question.QuestionSetting.MaximumOption < question.QuestionSetting.MinimumOption) {
 			code.AbortWithException(c, code.OptionNumError, errors.New("多选最多选项数小于最少选项数"))
-			return
+			return false
 		}
 		// 检查多选选项和最少选项数是否符合要求
-		if ((question.QuestionSetting.QuestionType == 2 && data.SurveyType == 0) ||
+		if ((question.QuestionSetting.QuestionType == 2 && data.SurveyType != 1) ||
 			(question.QuestionSetting.QuestionType == 1 && data.SurveyType == 1)) &&
 			uint(len(question.Options)) < question.QuestionSetting.MinimumOption {
 			code.AbortWithException(c, code.OptionNumError, errors.New("选项数量小于最少选项数"))
-			return
+			return false
 		}
 		// 检查最多选项数是否符合要求
-		if ((question.QuestionSetting.QuestionType == 2 && data.SurveyType == 0) ||
+		if ((question.QuestionSetting.QuestionType == 2 && data.SurveyType != 1) ||
 			(question.QuestionSetting.QuestionType == 1 && data.SurveyType == 1)) &&
 			question.QuestionSetting.MaximumOption == 0 {
 			code.AbortWithException(c, code.OptionNumError, errors.New("最多选项数小于等于0"))
-			return
+			return false
+		}
+		// 检查量表题的范围设置
+		if question.QuestionSetting.QuestionType == 8 {
+			if err := checkScale(question); err != nil {
+				code.AbortWithException(c, code.SurveyError, err)
+				return false
+			}
+		}
+		// 检查填空题的输入校验设置
+		if question.QuestionSetting.QuestionType == 3 || question.QuestionSetting.QuestionType == 4 {
+			if err := checkInput(question); err != nil {
+				code.AbortWithException(c, code.SurveyError, err)
+				return false
+			}
+		}
+		// 检查选项名额设置
+		if err := checkCapacity(question); err != nil {
+			code.AbortWithException(c, code.SurveyError, err)
+			return false
+		}
+		// 检查测验的分值和答案设置
+		if data.SurveyType == 2 {
+			if err := checkQuiz(question); err != nil {
+				code.AbortWithException(c, code.QuizError, err)
+				return false
+			}
 		}
 	}
 	// 检测问卷是否填写完整
 	if data.Status == 2 {
 		if data.QuestionConfig.Title == "" || len(data.QuestionConfig.QuestionList) == 0 {
 			code.AbortWithException(c, code.SurveyIncomplete, errors.New("问卷标题为空或问卷没有问题"))
-			return
+			return false
 		}
 		questionMap := make(map[string]bool)
 		for _, question := range data.QuestionConfig.QuestionList {
 			if question.Subject == "" {
 				code.AbortWithException(c, code.SurveyIncomplete,
 					errors.New("问题"+strconv.Itoa(question.SerialNum)+"标题为空"))
-				return
+				return false
 			}
 			if questionMap[question.Subject] {
 				code.AbortWithException(c, code.SurveyContentRepeat,
 					errors.New("问题"+strconv.Itoa(question.SerialNum)+"题目"+question.Subject+"重复"))
-				return
+				return false
 			}
 			questionMap[question.Subject] = true
-			if question.QuestionSetting.QuestionType == 1 || question.QuestionSetting.QuestionType == 2 {
+			if question.QuestionSetting.QuestionType == 1 || question.QuestionSetting.QuestionType == 2 ||
+				question.QuestionSetting.QuestionType == 9 {
 				if len(question.Options) < 1 {
 					code.AbortWithException(c, code.SurveyIncomplete,
 						errors.New("问题"+strconv.Itoa(question.SerialNum)+"选项数量太少"))
-					return
+					return false
 				}
 				optionMap := make(map[string]bool)
 				for _, option := range question.Options {
 					if option.Content == "" {
 						code.AbortWithException(c, code.SurveyIncomplete,
 							errors.New("选项"+strconv.Itoa(option.SerialNum)+"内容为空"))
-						return
+						return false
 					}
 					if optionMap[option.Content] {
 						code.AbortWithException(c, code.SurveyContentRepeat,
 							errors.New("选项内容"+option.Content+"重复"))
-						return
+						return false
 					}
 					optionMap[option.Content] = true
 				}
 			}
+			if question.QuestionSetting.QuestionType == 7 {
+				if err := checkMatrix(question); err != nil {
+					code.AbortWithException(c, code.SurveyIncomplete, err)
+					return false
+				}
+			}
 		}
 	}
-	// 创建问卷
-	err = service.CreateSurvey(user.ID, data.QuestionConfig.QuestionList, data.Status, data.SurveyType, data.BaseConfig.
-		DailyLimit, data.BaseConfig.SumLimit, data.BaseConfig.Verify, data.BaseConfig.UndergradOnly, ddlTime, startTime,
-		data.QuestionConfig.Title, data.QuestionConfig.Desc, data.BaseConfig.NeedNotify)
-	if err != nil {
-		code.AbortWithException(c, code.ServerError, err)
-		return
+	// 检查题目标识
+	if err := checkQuestionKeys(data.QuestionConfig.QuestionList); err != nil {
+		code.AbortWithException(c, code.SurveyError, err)
+		return false
 	}
-	utils.JsonSuccessResponse(c, nil)
+	// 检查问卷配额
+	if err := checkQuotas(data.BaseConfig); err != nil {
+		code.AbortWithException(c, code.QuotaError, err)
+		return false
+	}
+	// 检查投票结果公开范围
+	if err := checkResultVisibility(data.BaseConfig); err != nil {
+		code.AbortWithException(c, code.ParamError, err)
+		return false
+	}
+	// 检查问卷分页
+	if err := checkSections(data.QuestionConfig.QuestionList, data.QuestionConfig.Sections); err != nil {
+		code.AbortWithException(c, code.SectionError, err)
+		return false
+	}
+	// 检查题目显示逻辑
+	if err := checkLogic(data.QuestionConfig, data.BaseConfig.ShuffleQuestions); err != nil {
+		code.AbortWithException(c, code.LogicError, err)
+		return false
+	}
+	return true
 }
 
 type updateSurveyStatusData struct {
@@ -191,58 +253,10 @@ func UpdateSurveyStatus(c *gin.Context) {
 	}
 	// 检测问卷是否填写完整
 	if data.Status == 2 {
-		if survey.Title == "" {
-			code.AbortWithException(c, code.SurveyIncomplete, errors.New("问卷信息填写不完整"))
+		if apiErr, err := service.CheckSurveyComplete(survey); err != nil {
+			code.AbortWithException(c, apiErr, err)
 			return
 		}
-		questions, err := service.GetQuestionsBySurveyID(survey.ID)
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			code.AbortWithException(c, code.SurveyIncomplete, errors.New("问卷问题不存在"))
-			return
-		} else if err != nil {
-			code.AbortWithException(c, code.ServerError, err)
-			return
-		}
-		questionMap := make(map[string]bool)
-		for _, question := range questions {
-			if question.Subject == "" {
-				code.AbortWithException(c, code.SurveyIncomplete,
-					errors.New("问题"+strconv.Itoa(question.SerialNum)+"内容填写为空"))
-				return
-			}
-			if questionMap[question.Subject] {
-				code.AbortWithException(c, code.SurveyContentRepeat,
-					errors.New("问题题目"+question.Subject+"重复"))
-				return
-			}
-			questionMap[question.Subject] = true
-			if question.QuestionType == 1 || question.QuestionType == 2 {
-				options, err := service.GetOptionsByQuestionID(question.ID)
-				if err != nil {
-					code.AbortWithException(c, code.ServerError, err)
-					return
-				}
-				if len(options) < 1 {
-					code.AbortWithException(c, code.SurveyIncomplete,
-						errors.New("问题"+strconv.Itoa(question.ID)+"选项太少"))
-					return
-				}
-				optionMap := make(map[string]bool)
-				for _, option := range options {
-					if option.Content == "" {
-						code.AbortWithException(c, code.SurveyIncomplete,
-							errors.New("选项"+strconv.Itoa(option.SerialNum)+"内容未填"))
-						return
-					}
-					if optionMap[option.Content] {
-						code.AbortWithException(c, code.SurveyContentRepeat,
-							errors.New("选项内容"+option.Content+"重复"))
-						return
-					}
-					optionMap[option.Content] = true
-				}
-			}
-		}
 	}
 	// 修改问卷状态
 	err = service.UpdateSurveyStatus(data.ID, data.Status)
@@ -255,9 +269,9 @@ func UpdateSurveyStatus(c *gin.Context) {
 
 type updateSurveyData struct {
 	ID             int64              `json:"id" binding:"required"`
-	SurveyType     uint               `json:"survey_type"` // 问卷类型 0:调研 1:投票
-	BaseConfig     dao.BaseConfig     `json:"base_config"` // 基本配置
-	QuestionConfig dao.QuestionConfig `json:"ques_config"` // 问题设置
+	SurveyType     uint               `json:"survey_type" binding:"oneof=0 1 2"` // 问卷类型 0:调研 1:投票 2:测验
+	BaseConfig     dao.BaseConfig     `json:"base_config"`                       // 基本配置
+	QuestionConfig dao.QuestionConfig `json:"ques_config"`                       // 问题设置
 }
 
 // UpdateSurvey 修改问卷
@@ -286,79 +300,33 @@ func UpdateSurvey(c *gin.Context) {
 		code.AbortWithException(c, code.NoPermission, errors.New(user.Username+"无权限"))
 		return
 	}
-	// 判断问卷状态
-	if user.AdminType != 2 {
-		if survey.Status != 1 {
-			code.AbortWithException(c, code.StatusOpenError, errors.New("问卷状态不为未发布"))
-			return
-		}
-		// 判断问卷的填写数量是否为零
-		if survey.Num != 0 {
-			code.AbortWithException(c, code.SurveyNumError, errors.New("问卷已有填写数量"))
-			return
-		}
-	}
-	// 解析时间转换为中国时间(UTC+8)
-	ddlTime, err := time.Parse(time.RFC3339, data.BaseConfig.EndTime)
-	if err != nil {
-		code.AbortWithException(c, code.ServerError, err)
-		return
-	}
-	startTime, err := time.Parse(time.RFC3339, data.BaseConfig.StartTime)
-	if err != nil {
-		code.AbortWithException(c, code.ServerError, err)
-		return
-	}
-	if startTime.After(ddlTime) {
-		code.AbortWithException(c, code.SurveyError, errors.New("开始时间晚于截止时间"))
-		return
-	}
-	if data.BaseConfig.SumLimit != 0 && data.BaseConfig.DailyLimit != 0 &&
-		data.BaseConfig.SumLimit < data.BaseConfig.DailyLimit {
-		code.AbortWithException(c, code.SurveyError, errors.New("总投票次数小于单日投票次数"))
+	// 修改问卷与创建问卷的检查规则一致，但不要求问卷填写完整
+	if !checkCreateSurvey(c, createSurveyData{
+		Status:         1,
+		SurveyType:     data.SurveyType,
+		BaseConfig:     data.BaseConfig,
+		QuestionConfig: data.QuestionConfig,
+	}) {
 		return
 	}
-	// 检查问卷每个题目的序号没有重复且按照顺序递增
-	questionNumMap := make(map[int]bool)
-	for i, question := range data.QuestionConfig.QuestionList {
-		if questionNumMap[question.SerialNum] {
-			code.AbortWithException(c, code.SurveyError, errors.New("题目序号"+strconv.Itoa(question.SerialNum)+"重复"))
-			return
-		}
-		if i > 0 && question.SerialNum != data.QuestionConfig.QuestionList[i-1].SerialNum+1 {
-			code.AbortWithException(c, code.SurveyError, errors.New("题目序号不按顺序递增"))
-			return
-		}
-		questionNumMap[question.SerialNum] = true
-		question.SerialNum = i + 1
-
-		// 检测多选题目的最多选项数和最少选项数
-		if ((question.QuestionSetting.QuestionType == 2 && data.SurveyType == 0) ||
-			(question.QuestionSetting.QuestionType == 1 && data.SurveyType == 1)) &&
-			(question.QuestionSetting.MaximumOption < question.QuestionSetting.MinimumOption) {
-			code.AbortWithException(c, code.OptionNumError, errors.New("多选最多选项数小于最少选项数"))
-			return
-		}
-		// 检查多选选项和最少选项数是否符合要求
-		if ((question.QuestionSetting.QuestionType == 2 && data.SurveyType == 0) ||
-			(question.QuestionSetting.QuestionType == 1 && data.SurveyType == 1)) &&
-			uint(len(question.Options)) < question.QuestionSetting.MinimumOption {
-			code.AbortWithException(c, code.OptionNumError, errors.New("选项数量小于最少选项数"))
-			return
-		}
-		// 检查最多选项数是否符合要求
-		if ((question.QuestionSetting.QuestionType == 2 && data.SurveyType == 0) ||
-			(question.QuestionSetting.QuestionType == 1 && data.SurveyType == 1)) &&
-			question.QuestionSetting.MaximumOption == 0 {
-			code.AbortWithException(c, code.OptionNumError, errors.New("最多选项数小于等于0"))
-			return
+	// 已发布的问卷需要保证矩阵题完整
+	if survey.Status == 2 {
+		for _, question := range data.QuestionConfig.QuestionList {
+			if question.QuestionSetting.QuestionType != 7 {
+				continue
+			}
+			if err := checkMatrix(question); err != nil {
+				code.AbortWithException(c, code.SurveyIncomplete, err)
+				return
+			}
 		}
 	}
 	// 修改问卷
-	err = service.UpdateSurvey(data.ID, data.QuestionConfig.QuestionList, data.SurveyType, data.BaseConfig.DailyLimit,
-		data.BaseConfig.SumLimit, data.BaseConfig.Verify, data.BaseConfig.UndergradOnly, data.QuestionConfig.Desc,
-		data.QuestionConfig.Title, ddlTime, startTime, data.BaseConfig.NeedNotify)
-	if err != nil {
+	err = service.UpdateSurvey(data.ID, data.SurveyType, data.BaseConfig, data.QuestionConfig)
+	if errors.Is(err, service.ErrQuestionKeyMismatch) {
+		code.AbortWithException(c, code.SurveyError, err)
+		return
+	} else if err != nil {
 		code.AbortWithException(c, code.ServerError, err)
 		return
 	}
@@ -560,6 +528,13 @@ func GetSurvey(c *gin.Context) {
 		code.AbortWithException(c, code.ServerError, err)
 		return
 	}
+	// 获取问卷分页
+	sections, err := service.GetSectionsBySurveyID(survey.ID)
+	if err != nil {
+		code.AbortWithException(c, code.ServerError, err)
+		return
+	}
+	questions = service.ArrangeQuestions(survey, sections, questions, "")
 	// 构建问卷响应
 	questionListsResponse := make([]map[string]any, 0)
 	for _, question := range questions {
@@ -568,7 +543,9 @@ func GetSurvey(c *gin.Context) {
 			code.AbortWithException(c, code.ServerError, err)
 			return
 		}
+		options = service.ArrangeOptions(question, options, "")
 		optionsResponse := make([]map[string]any, 0)
+		columnsResponse := make([]map[string]any, 0)
 		for _, option := range options {
 			optionResponse := map[string]any{
 				"id":          option.ID,
@@ -576,50 +553,90 @@ func GetSurvey(c *gin.Context) {
 				"content":     option.Content,
 				"img":         option.Img,
 				"description": option.Description,
+				"capacity":    option.Capacity,
+				"is_correct":  option.IsCorrect,
+				"score":       option.Score,
+			}
+			if option.IsColumn {
+				columnsResponse = append(columnsResponse, optionResponse)
+				continue
 			}
 			optionsResponse = append(optionsResponse, optionResponse)
 		}
 
 		questionSettingResponse := map[string]any{
-			"required":       question.Required,
-			"unique":         question.Unique,
-			"other_option":   question.OtherOption,
-			"question_type":  question.QuestionType,
-			"reg":            question.Reg,
-			"maximum_option": question.MaximumOption,
-			"minimum_option": question.MinimumOption,
+			"required":        question.Required,
+			"unique":          question.Unique,
+			"other_option":    question.OtherOption,
+			"question_type":   question.QuestionType,
+			"reg":             question.Reg,
+			"shuffle_options": question.ShuffleOptions,
+			"input_type":      question.InputType,
+			"maximum_option":  question.MaximumOption,
+			"minimum_option":  question.MinimumOption,
+			"scale_type":      question.ScaleType,
+			"min_value":       question.MinValue,
+			"max_value":       question.MaxValue,
+			"step":            question.Step,
+			"score":           question.Score,
+			"answer_key":      question.AnswerKey,
 		}
 
 		questionListMap := map[string]any{
 			"id":           question.ID,
+			"key":          service.QuestionKey(question),
 			"serial_num":   question.SerialNum,
+			"section_num":  question.SectionNum,
 			"subject":      question.Subject,
 			"description":  question.Description,
 			"img":          question.Img,
 			"ques_setting": questionSettingResponse,
 			"options":      optionsResponse,
+			"columns":      columnsResponse,
 		}
 		questionListsResponse = append(questionListsResponse, questionListMap)
 	}
 
+	// 获取题目显示逻辑
+	rules, err := service.GetRulesBySurveyID(survey.ID)
+	if err != nil {
+		code.AbortWithException(c, code.ServerError, err)
+		return
+	}
+	// 获取问卷配额
+	quotas, err := service.GetQuotasBySurveyID(survey.ID)
+	if err != nil {
+		code.AbortWithException(c, code.ServerError, err)
+		return
+	}
+
 	questionsConfigResponse := map[string]any{
 		"title":         survey.Title,
 		"desc":          survey.Desc,
 		"question_list": questionListsResponse,
+		"logic":         service.GetLogicResponse(rules),
+		"sections":      service.GetSectionResponse(sections, questions),
 	}
 	baseConfigResponse := map[string]any{
-		"start_time":     survey.StartTime,
-		"end_time":       survey.Deadline,
-		"day_limit":      survey.DailyLimit,
-		"sum_limit":      survey.SumLimit,
-		"verify":         survey.Verify,
-		"undergrad_only": survey.UndergradOnly,
-		"need_notify":    survey.NeedNotify,
+		"start_time":        survey.StartTime,
+		"end_time":          survey.Deadline,
+		"day_limit":         survey.DailyLimit,
+		"sum_limit":         survey.SumLimit,
+		"verify":            survey.Verify,
+		"undergrad_only":    survey.UndergradOnly,
+		"need_notify":       survey.NeedNotify,
+		"shuffle_questions": survey.ShuffleQuestions,
+		"quotas":            service.GetQuotaResponse(quotas),
+		"show_score":        survey.ShowScore,
+		"auto_publish":      survey.AutoPublish,
+		"hide_live_results": survey.HideLiveResults,
+		"result_visibility": survey.ResultVisibility,
 	}
 	response := map[string]any{
 		"id":          survey.ID,
 		"status":      survey.Status,
 		"survey_type": survey.Type,
+		"version":     survey.Version,
 		"base_config": baseConfigResponse,
 		"ques_config": questionsConfigResponse,
 	}
@@ -662,7 +679,16 @@ func DownloadFile(c *gin.Context) {
 		code.AbortWithException(c, code.ServerError, err)
 		return
 	}
-	url, err := service.HandleDownloadFile(answers, survey)
+	// 测验另附得分分布
+	var scoreStats *service.GetScoreStatistics
+	if survey.Type == 2 {
+		scoreStats, err = service.GetSurveyScoreStats(data.ID)
+		if err != nil {
+			code.AbortWithException(c, code.ServerError, err)
+			return
+		}
+	}
+	url, err := service.HandleDownloadFile(answers, survey, scoreStats)
 	if err != nil {
 		code.AbortWithException(c, code.ServerError, err)
 		return
@@ -702,19 +728,25 @@ func GetSurveyStatistics(c *gin.Context) {
 		return
 	}
 
-	answersheets, err := service.GetSurveyAnswersBySurveyID(data.ID)
+	response, err := service.GetSurveyStatistics(data.ID)
 	if err != nil {
 		code.AbortWithException(c, code.ServerError, err)
 		return
 	}
-
-	questions, err := service.GetQuestionsBySurveyID(data.ID)
+	total, err := service.CountAnswerSheets(data.ID)
 	if err != nil {
 		code.AbortWithException(c, code.ServerError, err)
 		return
 	}
-
-	response := service.GenerateQuestionStats(questions, answersheets)
+	// 测验返回得分分布
+	var scoreStats *service.GetScoreStatistics
+	if survey.Type == 2 {
+		scoreStats, err = service.GetSurveyScoreStats(data.ID)
+		if err != nil {
+			code.AbortWithException(c, code.ServerError, err)
+			return
+		}
+	}
 	start := (data.PageNum - 1) * data.PageSize
 	end := start + data.PageSize
 	// 确保 start 和 end 在有效范围内
@@ -734,9 +766,10 @@ func GetSurveyStatistics(c *gin.Context) {
 
 	utils.JsonSuccessResponse(c, gin.H{
 		"statistics":     resp,
-		"total":          len(answersheets),
+		"total":          total,
 		"total_sum_page": totalSumPage,
 		"survey_type":    survey.Type,
+		"score":          scoreStats,
 	})
 }
 
@@ -832,7 +865,7 @@ func DeleteAnswerSheet(c *gin.Context) {
 		code.AbortWithException(c, code.ServerError, err)
 	}
 	// 获取问卷
-	err = service.GetAnswerSheetByAnswerID(objectID)
+	_, err = service.GetAnswerSheetByAnswerID(objectID)
 	if errors.Is(err, mongo.ErrNoDocuments) {
 		code.AbortWithException(c, code.AnswerSheetNotExist, errors.New("答卷不存在"))
 		return
@@ -884,21 +917,495 @@ func DownloadChooseFile(c *gin.Context) {
 		return
 	}
 	// 获取数据
-	answers, err := service.GetSurveyAnswersBySurveyID(data.ID)
+	stats, err := service.GetSurveyStatistics(data.ID)
 	if err != nil {
 		code.AbortWithException(c, code.ServerError, err)
 		return
 	}
-	questions, err := service.GetQuestionsBySurveyID(data.ID)
+	url, err := service.HandleChooseStatistics(survey, stats)
 	if err != nil {
 		code.AbortWithException(c, code.ServerError, err)
 		return
 	}
-	stats := service.GenerateQuestionStats(questions, answers)
-	url, err := service.HandleChooseStatistics(survey, stats)
+	utils.JsonSuccessResponse(c, url)
+}
+
+type timelineData struct {
+	ID        int64  `form:"id" binding:"required"`
+	Interval  string `form:"interval" binding:"required,oneof=hour day"` // 统计间隔 hour:按小时 day:按天
+	SerialNum int    `form:"serial_num"`                                 // 筛选答卷的题目序号
+	Answer    string `form:"answer"`                                     // 筛选答卷的答案内容
+}
+
+// GetSubmissionTimeline 获取问卷答卷数量随时间的分布及累计曲线
+func GetSubmissionTimeline(c *gin.Context) {
+	var data timelineData
+	err := c.ShouldBindQuery(&data)
+	if err != nil {
+		code.AbortWithException(c, code.ParamError, err)
+		return
+	}
+	if data.SerialNum != 0 && data.Answer == "" {
+		code.AbortWithException(c, code.ParamError, errors.New("按题目筛选时需指定答案"))
+		return
+	}
+	user, err := service.GetUserSession(c)
+	if err != nil {
+		code.AbortWithException(c, code.NotLogin, err)
+		return
+	}
+	survey, ok := getPermittedSurvey(c, user, data.ID)
+	if !ok {
+		return
+	}
+	points, total, err := service.GetSubmissionTimeline(survey.ID, data.Interval, data.SerialNum, data.Answer)
+	if errors.Is(err, service.ErrTimelineQuestion) {
+		code.AbortWithException(c, code.ParamError, err)
+		return
+	} else if err != nil {
+		code.AbortWithException(c, code.ServerError, err)
+		return
+	}
+	utils.JsonSuccessResponse(c, gin.H{
+		"interval": data.Interval,
+		"timeline": points,
+		"total":    total,
+	})
+}
+
+// crossTabData 交叉分析请求，行维度为选择题，列维度为另一道选择题或答题者信息
+type crossTabData struct {
+	ID           int64  `form:"id" binding:"required"`
+	RowSerial    int    `form:"row_serial" binding:"required"`
+	ColumnSerial int    `form:"column_serial"`
+	ColumnField  string `form:"column_field" binding:"omitempty,oneof=college gender user_type_desc"`
+}
+
+// GetCrossTab 交叉分析两道选择题，或选择题与答题者信息
+func GetCrossTab(c *gin.Context) {
+	survey, tab, ok := crossTabulate(c)
+	if !ok {
+		return
+	}
+	utils.JsonSuccessResponse(c, gin.H{
+		"title":    survey.Title,
+		"crosstab": tab,
+	})
+}
+
+// DownloadCrossTabFile 下载交叉分析数据
+func DownloadCrossTabFile(c *gin.Context) {
+	survey, tab, ok := crossTabulate(c)
+	if !ok {
+		return
+	}
+	url, err := service.HandleCrossTabStatistics(survey, tab)
 	if err != nil {
 		code.AbortWithException(c, code.ServerError, err)
 		return
 	}
 	utils.JsonSuccessResponse(c, url)
 }
+
+// crossTabulate 检查交叉分析请求并计算结果，失败时直接返回错误
+func crossTabulate(c *gin.Context) (*model.Survey, *service.CrossTabResponse, bool) {
+	var data crossTabData
+	err := c.ShouldBindQuery(&data)
+	if err != nil {
+		code.AbortWithException(c, code.ParamError, err)
+		return nil, nil, false
+	}
+	if (data.ColumnSerial == 0) == (data.ColumnField == "") {
+		code.AbortWithException(c, code.CrossTabError, errors.New("列维度需指定选择题或答题者信息之一"))
+		return nil, nil, false
+	}
+	if data.ColumnSerial == data.RowSerial {
+		code.AbortWithException(c, code.CrossTabError, errors.New("行列维度不能为同一道题"))
+		return nil, nil, false
+	}
+	user, err := service.GetUserSession(c)
+	if err != nil {
+		code.AbortWithException(c, code.NotLogin, err)
+		return nil, nil, false
+	}
+	survey, ok := getPermittedSurvey(c, user, data.ID)
+	if !ok {
+		return nil, nil, false
+	}
+	if data.ColumnField != "" && !survey.Verify {
+		code.AbortWithException(c, code.CrossTabError, errors.New("问卷未开启统一验证，没有答题者信息"))
+		return nil, nil, false
+	}
+	tab, err := service.CrossTabulate(survey,
+		service.CrossTabDimension{SerialNum: data.RowSerial},
+		service.CrossTabDimension{SerialNum: data.ColumnSerial, Field: data.ColumnField})
+	if errors.Is(err, service.ErrCrossTabDimension) {
+		code.AbortWithException(c, code.CrossTabError, err)
+		return nil, nil, false
+	} else if err != nil {
+		code.AbortWithException(c, code.ServerError, err)
+		return nil, nil, false
+	}
+	return survey, tab, true
+}
+
+// checkLogic 检查题目显示逻辑是否合法，shuffle 为问卷是否打乱全部分页的题目顺序
+// 显示逻辑依赖题目的先后顺序，打乱顺序的分页不能包含条件题目或目标题目
+func checkLogic(config dao.QuestionConfig, shuffle bool) error {
+	shuffled := make(map[int]bool)
+	for _, section := range config.Sections {
+		shuffled[section.SerialNum] = section.ShuffleQuestions
+	}
+	questionMap := make(map[int]dao.QuestionList)
+	// 记录每个分页的第一道题目序号
+	sectionStart := make(map[int]int)
+	for _, question := range config.QuestionList {
+		questionMap[question.SerialNum] = question
+		if start, ok := sectionStart[question.SectionNum]; !ok || question.SerialNum < start {
+			sectionStart[question.SectionNum] = question.SerialNum
+		}
+	}
+	for _, rule := range config.Logic {
+		question, ok := questionMap[rule.SerialNum]
+		if !ok {
+			return errors.New("条件题目" + strconv.Itoa(rule.SerialNum) + "不存在")
+		}
+		if shuffle || shuffled[question.SectionNum] {
+			return errors.New("条件题目" + strconv.Itoa(rule.SerialNum) + "所在分页打乱了题目顺序")
+		}
+		if rule.TargetSection != 0 {
+			start, ok := sectionStart[rule.TargetSection]
+			if !ok {
+				return errors.New("目标分页" + strconv.Itoa(rule.TargetSection) + "不存在或没有题目")
+			}
+			if start <= rule.SerialNum {
+				return errors.New("目标分页" + strconv.Itoa(rule.TargetSection) + "必须在条件题目之后")
+			}
+		} else {
+			target, ok := questionMap[rule.TargetSerial]
+			if !ok {
+				return errors.New("目标题目" + strconv.Itoa(rule.TargetSerial) + "不存在")
+			}
+			if shuffled[target.SectionNum] {
+				return errors.New("目标题目" + strconv.Itoa(rule.TargetSerial) + "所在分页打乱了题目顺序")
+			}
+			if rule.TargetSerial <= rule.SerialNum {
+				return errors.New("目标题目" + strconv.Itoa(rule.TargetSerial) + "必须在条件题目之后")
+			}
+		}
+		if rule.Action < 1 || rule.Action > 3 {
+			return errors.New("显示逻辑动作" + strconv.Itoa(rule.Action) + "不存在")
+		}
+		switch rule.MatchType {
+		case 1:
+			if question.QuestionSetting.QuestionType != 1 && question.QuestionSetting.QuestionType != 2 {
+				return errors.New("题目" + strconv.Itoa(rule.SerialNum) + "不是选择题")
+			}
+			found := false
+			for _, option := range question.Options {
+				if option.SerialNum == rule.OptionSerial {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return errors.New("题目" + strconv.Itoa(rule.SerialNum) + "的选项" +
+					strconv.Itoa(rule.OptionSerial) + "不存在")
+			}
+		case 2:
+			if rule.Text == "" {
+				return errors.New("题目" + strconv.Itoa(rule.SerialNum) + "的匹配文本为空")
+			}
+		default:
+			return errors.New("显示逻辑条件类型" + strconv.Itoa(rule.MatchType) + "不存在")
+		}
+	}
+	return nil
+}
+
+// checkQuestionKeys 检查题目标识没有重复
+func checkQuestionKeys(questionList []dao.QuestionList) error {
+	keyMap := make(map[string]bool)
+	for _, question := range questionList {
+		if question.Key == "" {
+			continue
+		}
+		if keyMap[question.Key] {
+			return errors.New("问题" + strconv.Itoa(question.SerialNum) + "的题目标识重复")
+		}
+		keyMap[question.Key] = true
+	}
+	return nil
+}
+
+// checkResultVisibility 检查投票结果公开范围，提交后公开和仅统一验证用户可见依赖统一验证
+func checkResultVisibility(config dao.BaseConfig) error {
+	visibility := config.ResultVisibility
+	if visibility > service.ResultVisibleVerified {
+		return errors.New("结果公开范围不存在")
+	}
+	if (visibility == service.ResultVisibleAfterSubmit || visibility == service.ResultVisibleVerified) &&
+		!config.Verify {
+		return errors.New("该结果公开范围需要开启统一验证")
+	}
+	return nil
+}
+
+// checkQuotas 检查问卷配额是否合法，配额依赖统一验证获取答题者信息
+func checkQuotas(config dao.BaseConfig) error {
+	if len(config.Quotas) > 0 && !config.Verify {
+		return errors.New("设置配额的问卷需要开启统一验证")
+	}
+	quotaMap := make(map[string]bool)
+	for _, quota := range config.Quotas {
+		if !service.QuotaFields[quota.Field] {
+			return errors.New("配额字段" + quota.Field + "不存在")
+		}
+		if quota.Value == "" {
+			return errors.New("配额字段" + quota.Field + "的取值为空")
+		}
+		if quota.Limit <= 0 {
+			return errors.New("配额" + quota.Field + ":" + quota.Value + "的上限必须大于0")
+		}
+		key := quota.Field + ":" + quota.Value
+		if quotaMap[key] {
+			return errors.New("配额" + key + "重复")
+		}
+		quotaMap[key] = true
+	}
+	return nil
+}
+
+// checkSections 检查问卷分页是否合法，分页内的题目需按序号连续排列
+func checkSections(questionList []dao.QuestionList, sections []dao.Section) error {
+	sectionMap := make(map[int]bool)
+	for _, section := range sections {
+		if section.SerialNum <= 0 {
+			return errors.New("分页序号" + strconv.Itoa(section.SerialNum) + "必须大于0")
+		}
+		if sectionMap[section.SerialNum] {
+			return errors.New("分页序号" + strconv.Itoa(section.SerialNum) + "重复")
+		}
+		sectionMap[section.SerialNum] = true
+	}
+	sorted := make([]dao.QuestionList, len(questionList))
+	copy(sorted, questionList)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].SerialNum < sorted[j].SerialNum
+	})
+	lastSection := 0
+	for _, question := range sorted {
+		if len(sections) == 0 {
+			if question.SectionNum != 0 {
+				return errors.New("问题" + strconv.Itoa(question.SerialNum) + "所属分页不存在")
+			}
+			continue
+		}
+		if !sectionMap[question.SectionNum] {
+			return errors.New("问题" + strconv.Itoa(question.SerialNum) + "所属分页不存在")
+		}
+		if question.SectionNum < lastSection {
+			return errors.New("问题" + strconv.Itoa(question.SerialNum) + "与所属分页顺序不一致")
+		}
+		lastSection = question.SectionNum
+	}
+	return nil
+}
+
+// checkMatrix 检查矩阵题的行和列是否完整
+func checkMatrix(question dao.QuestionList) error {
+	if len(question.Options) < 1 || len(question.Columns) < 1 {
+		return errors.New("问题" + strconv.Itoa(question.SerialNum) + "矩阵行或列太少")
+	}
+	for _, options := range [][]dao.Option{question.Options, question.Columns} {
+		serialMap := make(map[int]bool)
+		contentMap := make(map[string]bool)
+		for _, option := range options {
+			if option.Content == "" {
+				return errors.New("问题" + strconv.Itoa(question.SerialNum) + "矩阵内容为空")
+			}
+			if serialMap[option.SerialNum] || contentMap[option.Content] {
+				return errors.New("问题" + strconv.Itoa(question.SerialNum) + "矩阵内容" + option.Content + "重复")
+			}
+			serialMap[option.SerialNum] = true
+			contentMap[option.Content] = true
+		}
+	}
+	return nil
+}
+
+// checkScale 检查量表题的类型和范围设置
+func checkScale(question dao.QuestionList) error {
+	setting := question.QuestionSetting
+	switch setting.ScaleType {
+	case 1, 3:
+		if setting.MaxValue <= setting.MinValue {
+			return errors.New("问题" + strconv.Itoa(question.SerialNum) + "最大值必须大于最小值")
+		}
+		if setting.Step <= 0 {
+			return errors.New("问题" + strconv.Itoa(question.SerialNum) + "步长必须大于0")
+		}
+		// 评分统计时列出全部刻度，限制刻度数量
+		if setting.ScaleType == 1 && (setting.MaxValue-setting.MinValue)/setting.Step >= service.MaxScalePoints {
+			return errors.New("问题" + strconv.Itoa(question.SerialNum) + "刻度数量不能超过" +
+				strconv.Itoa(service.MaxScalePoints))
+		}
+	case 2:
+		// NPS 固定为 0-10 分，无需设置范围
+	default:
+		return errors.New("问题" + strconv.Itoa(question.SerialNum) + "量表类型不存在")
+	}
+	return nil
+}
+
+// checkInput 检查填空题的输入类型和正则表达式设置
+func checkInput(question dao.QuestionList) error {
+	setting := question.QuestionSetting
+	if setting.Reg != "" {
+		if _, err := regexp.Compile(setting.Reg); err != nil {
+			return errors.New("问题" + strconv.Itoa(question.SerialNum) + "正则表达式不合法")
+		}
+	}
+	if setting.InputType < 0 || setting.InputType > 7 || (setting.QuestionType == 4 && setting.InputType != 0) {
+		return errors.New("问题" + strconv.Itoa(question.SerialNum) + "输入类型不存在")
+	}
+	if (setting.InputType == 3 || setting.InputType == 4) && setting.MaxValue < setting.MinValue {
+		return errors.New("问题" + strconv.Itoa(question.SerialNum) + "最大值不能小于最小值")
+	}
+	return nil
+}
+
+// checkCapacity 检查选项名额设置，仅单选和多选题的选项可以限额
+func checkCapacity(question dao.QuestionList) error {
+	questionType := question.QuestionSetting.QuestionType
+	for _, option := range question.Options {
+		if option.Capacity < 0 {
+			return errors.New("问题" + strconv.Itoa(question.SerialNum) + "选项名额不能小于0")
+		}
+		if option.Capacity > 0 && questionType != 1 && questionType != 2 {
+			return errors.New("问题" + strconv.Itoa(question.SerialNum) + "不是选择题，不能设置选项名额")
+		}
+	}
+	return nil
+}
+
+type getQuotaProgressData struct {
+	ID int64 `form:"id" binding:"required"`
+}
+
+// GetQuotaProgress 获取问卷配额进度
+func GetQuotaProgress(c *gin.Context) {
+	var data getQuotaProgressData
+	err := c.ShouldBindQuery(&data)
+	if err != nil {
+		code.AbortWithException(c, code.ParamError, err)
+		return
+	}
+	user, err := service.GetUserSession(c)
+	if err != nil {
+		code.AbortWithException(c, code.NotLogin, err)
+		return
+	}
+	// 获取问卷
+	survey, err := service.GetSurveyByID(data.ID)
+	if err != nil {
+		code.AbortWithException(c, code.ServerError, err)
+		return
+	}
+	// 判断权限
+	if (user.AdminType != 2) && (user.AdminType != 1 || survey.UserID != user.ID) &&
+		!service.UserInManage(user.ID, survey.ID) {
+		code.AbortWithException(c, code.NoPermission, errors.New(user.Username+"无权限"))
+		return
+	}
+	progress, err := service.GetQuotaProgressBySurveyID(survey.ID)
+	if err != nil {
+		code.AbortWithException(c, code.ServerError, err)
+		return
+	}
+	utils.JsonSuccessResponse(c, gin.H{
+		"quotas": progress,
+	})
+}
+
+// checkQuiz 检查测验题目的分值和标准答案设置
+func checkQuiz(question dao.QuestionList) error {
+	setting := question.QuestionSetting
+	if setting.Score < 0 {
+		return errors.New("问题" + strconv.Itoa(question.SerialNum) + "分值不能小于0")
+	}
+	// 选项分值可以为负，用于多选题中选择错误选项时扣分
+	hasCorrect, hasOptionScore := false, false
+	for _, option := range question.Options {
+		hasCorrect = hasCorrect || option.IsCorrect
+		hasOptionScore = hasOptionScore || option.Score > 0
+	}
+	switch setting.QuestionType {
+	case 1, 2:
+		if setting.Score > 0 && !hasCorrect && !hasOptionScore {
+			return errors.New("问题" + strconv.Itoa(question.SerialNum) + "未设置正确选项")
+		}
+	case 3:
+		if setting.Score > 0 && setting.AnswerKey == "" {
+			return errors.New("问题" + strconv.Itoa(question.SerialNum) + "未设置标准答案")
+		}
+	default:
+		if setting.Score > 0 || hasOptionScore {
+			return errors.New("问题" + strconv.Itoa(question.SerialNum) + "不支持自动评分")
+		}
+	}
+	return nil
+}
+
+type cloneSurveyData struct {
+	ID        int64  `json:"id" binding:"required"`
+	Title     string `json:"title"`
+	StartTime string `json:"start_time" binding:"omitempty,datetime=2006-01-02T15:04:05+08:00"`
+}
+
+// CloneSurvey 复制问卷，新问卷为未发布状态并归属于当前管理员
+func CloneSurvey(c *gin.Context) {
+	var data cloneSurveyData
+	err := c.ShouldBindJSON(&data)
+	if err != nil {
+		code.AbortWithException(c, code.ParamError, err)
+		return
+	}
+	// 鉴权
+	user, err := service.GetUserSession(c)
+	if err != nil {
+		code.AbortWithException(c, code.NotLogin, err)
+		return
+	}
+	// 获取问卷
+	survey, err := service.GetSurveyByID(data.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		code.AbortWithException(c, code.SurveyNotExist, errors.New("问卷不存在"))
+		return
+	} else if err != nil {
+		code.AbortWithException(c, code.ServerError, err)
+		return
+	}
+	// 判断权限
+	if (user.AdminType != 2) && (user.AdminType != 1 || survey.UserID != user.ID) &&
+		!service.UserInManage(user.ID, survey.ID) {
+		code.AbortWithException(c, code.NoPermission, errors.New(user.Username+"无权限"))
+		return
+	}
+	var startTime time.Time
+	if data.StartTime != "" {
+		startTime, err = time.Parse(time.RFC3339, data.StartTime)
+		if err != nil {
+			code.AbortWithException(c, code.ParamError, err)
+			return
+		}
+	}
+	sid, err := service.CloneSurvey(survey, user.ID, data.Title, startTime)
+	if err != nil {
+		code.AbortWithException(c, code.ServerError, err)
+		return
+	}
+	utils.JsonSuccessResponse(c, gin.H{
+		"id": sid,
+	})
+}
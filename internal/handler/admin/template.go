@@ -0,0 +1,188 @@
+package admin
+
+import (
+	"errors"
+
+	"QA-System/internal/pkg/code"
+	"QA-System/internal/pkg/utils"
+	"QA-System/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type createTemplateData struct {
+	SurveyID int64  `json:"survey_id" binding:"required"`
+	Title    string `json:"title"`
+	IsShared bool   `json:"is_shared"` // 是否为组织模板，仅超级管理员可创建
+}
+
+// CreateTemplate 将已有问卷保存为模板
+func CreateTemplate(c *gin.Context) {
+	var data createTemplateData
+	err := c.ShouldBindJSON(&data)
+	if err != nil {
+		code.AbortWithException(c, code.ParamError, err)
+		return
+	}
+	// 鉴权
+	user, err := service.GetUserSession(c)
+	if err != nil {
+		code.AbortWithException(c, code.NotLogin, err)
+		return
+	}
+	if data.IsShared && user.AdminType != 2 {
+		code.AbortWithException(c, code.NoPermission, errors.New(user.Username+"无权限创建组织模板"))
+		return
+	}
+	// 获取问卷
+	survey, err := service.GetSurveyByID(data.SurveyID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		code.AbortWithException(c, code.SurveyNotExist, errors.New("问卷不存在"))
+		return
+	} else if err != nil {
+		code.AbortWithException(c, code.ServerError, err)
+		return
+	}
+	// 判断权限
+	if (user.AdminType != 2) && (user.AdminType != 1 || survey.UserID != user.ID) &&
+		!service.UserInManage(user.ID, survey.ID) {
+		code.AbortWithException(c, code.NoPermission, errors.New(user.Username+"无权限"))
+		return
+	}
+	definition, err := service.GetSurveyDefinition(survey)
+	if err != nil {
+		code.AbortWithException(c, code.ServerError, err)
+		return
+	}
+	if data.Title == "" {
+		data.Title = survey.Title
+	}
+	err = service.CreateTemplate(user.ID, data.Title, data.IsShared, definition)
+	if err != nil {
+		code.AbortWithException(c, code.ServerError, err)
+		return
+	}
+	utils.JsonSuccessResponse(c, nil)
+}
+
+// GetTemplates 获取当前管理员可见的模板列表
+func GetTemplates(c *gin.Context) {
+	user, err := service.GetUserSession(c)
+	if err != nil {
+		code.AbortWithException(c, code.NotLogin, err)
+		return
+	}
+	templates, err := service.GetTemplatesByUserID(user.ID)
+	if err != nil {
+		code.AbortWithException(c, code.ServerError, err)
+		return
+	}
+	utils.JsonSuccessResponse(c, gin.H{
+		"template_list": templates,
+	})
+}
+
+type useTemplateData struct {
+	TemplateID int    `json:"template_id" binding:"required"`
+	Title      string `json:"title"`
+	StartTime  string `json:"start_time" binding:"omitempty,datetime=2006-01-02T15:04:05+08:00"`
+	EndTime    string `json:"end_time" binding:"omitempty,datetime=2006-01-02T15:04:05+08:00"`
+}
+
+// CreateSurveyByTemplate 根据模板创建问卷，新问卷为未发布状态
+func CreateSurveyByTemplate(c *gin.Context) {
+	var data useTemplateData
+	err := c.ShouldBindJSON(&data)
+	if err != nil {
+		code.AbortWithException(c, code.ParamError, err)
+		return
+	}
+	// 鉴权
+	user, err := service.GetUserSession(c)
+	if err != nil {
+		code.AbortWithException(c, code.NotLogin, err)
+		return
+	}
+	template, err := service.GetTemplateByID(data.TemplateID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		code.AbortWithException(c, code.TemplateNotExist, errors.New("模板不存在"))
+		return
+	} else if err != nil {
+		code.AbortWithException(c, code.ServerError, err)
+		return
+	}
+	if !template.IsShared && template.UserID != user.ID {
+		code.AbortWithException(c, code.NoPermission, errors.New(user.Username+"无权限"))
+		return
+	}
+	definition, err := service.GetTemplateDefinition(template)
+	if err != nil {
+		code.AbortWithException(c, code.ServerError, err)
+		return
+	}
+	if data.Title != "" {
+		definition.QuestionConfig.Title = data.Title
+	}
+	if data.StartTime != "" {
+		definition.BaseConfig.StartTime = data.StartTime
+	}
+	if data.EndTime != "" {
+		definition.BaseConfig.EndTime = data.EndTime
+	}
+	check := createSurveyData{
+		Status:         1,
+		SurveyType:     definition.SurveyType,
+		BaseConfig:     definition.BaseConfig,
+		QuestionConfig: definition.QuestionConfig,
+	}
+	if !checkCreateSurvey(c, check) {
+		return
+	}
+	sid, err := service.CreateSurveyByTemplate(user.ID, definition)
+	if err != nil {
+		code.AbortWithException(c, code.ServerError, err)
+		return
+	}
+	utils.JsonSuccessResponse(c, gin.H{
+		"id": sid,
+	})
+}
+
+type deleteTemplateData struct {
+	ID int `form:"id" binding:"required"`
+}
+
+// DeleteTemplate 删除模板
+func DeleteTemplate(c *gin.Context) {
+	var data deleteTemplateData
+	err := c.ShouldBindQuery(&data)
+	if err != nil {
+		code.AbortWithException(c, code.ParamError, err)
+		return
+	}
+	// 鉴权
+	user, err := service.GetUserSession(c)
+	if err != nil {
+		code.AbortWithException(c, code.NotLogin, err)
+		return
+	}
+	template, err := service.GetTemplateByID(data.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		code.AbortWithException(c, code.TemplateNotExist, errors.New("模板不存在"))
+		return
+	} else if err != nil {
+		code.AbortWithException(c, code.ServerError, err)
+		return
+	}
+	if user.AdminType != 2 && template.UserID != user.ID {
+		code.AbortWithException(c, code.NoPermission, errors.New(user.Username+"无权限"))
+		return
+	}
+	err = service.DeleteTemplate(data.ID)
+	if err != nil {
+		code.AbortWithException(c, code.ServerError, err)
+		return
+	}
+	utils.JsonSuccessResponse(c, nil)
+}
@@ -0,0 +1,126 @@
+package admin
+
+import (
+	"errors"
+
+	"QA-System/internal/dao"
+	"QA-System/internal/pkg/code"
+	"QA-System/internal/pkg/utils"
+	"QA-System/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type saveTranslationData struct {
+	SurveyID int64 `json:"survey_id" binding:"required"`
+	dao.Translation
+}
+
+// SaveTranslation 保存问卷某一语言的翻译，覆盖该语言原有的翻译
+func SaveTranslation(c *gin.Context) {
+	var data saveTranslationData
+	err := c.ShouldBindJSON(&data)
+	if err != nil {
+		code.AbortWithException(c, code.ParamError, err)
+		return
+	}
+	user, err := service.GetUserSession(c)
+	if err != nil {
+		code.AbortWithException(c, code.NotLogin, err)
+		return
+	}
+	survey, ok := getPermittedSurvey(c, user, data.SurveyID)
+	if !ok {
+		return
+	}
+	// 翻译的题目必须属于当前版本的问卷
+	questions, err := service.GetQuestionsBySurveyID(survey.ID)
+	if err != nil {
+		code.AbortWithException(c, code.ServerError, err)
+		return
+	}
+	keys := make(map[string]bool)
+	for _, question := range questions {
+		keys[service.QuestionKey(question)] = true
+	}
+	for _, question := range data.Questions {
+		if !keys[question.Key] {
+			code.AbortWithException(c, code.TranslationError, errors.New("题目"+question.Key+"不存在"))
+			return
+		}
+	}
+	err = service.SaveTranslation(survey.ID, data.Translation)
+	if err != nil {
+		code.AbortWithException(c, code.ServerError, err)
+		return
+	}
+	utils.JsonSuccessResponse(c, nil)
+}
+
+type translationData struct {
+	SurveyID int64  `form:"survey_id" binding:"required"`
+	Locale   string `form:"locale"`
+}
+
+// GetTranslation 获取问卷已有翻译的语言，指定语言时同时返回该语言的翻译
+func GetTranslation(c *gin.Context) {
+	var data translationData
+	err := c.ShouldBindQuery(&data)
+	if err != nil {
+		code.AbortWithException(c, code.ParamError, err)
+		return
+	}
+	user, err := service.GetUserSession(c)
+	if err != nil {
+		code.AbortWithException(c, code.NotLogin, err)
+		return
+	}
+	survey, ok := getPermittedSurvey(c, user, data.SurveyID)
+	if !ok {
+		return
+	}
+	locales, err := service.GetTranslationLocales(survey.ID)
+	if err != nil {
+		code.AbortWithException(c, code.ServerError, err)
+		return
+	}
+	response := gin.H{"locales": locales}
+	if data.Locale != "" {
+		translation, err := service.GetTranslation(survey.ID, data.Locale)
+		if err != nil {
+			code.AbortWithException(c, code.ServerError, err)
+			return
+		}
+		response["translation"] = translation
+	}
+	utils.JsonSuccessResponse(c, response)
+}
+
+// DeleteTranslation 删除问卷某一语言的翻译
+func DeleteTranslation(c *gin.Context) {
+	var data translationData
+	err := c.ShouldBindQuery(&data)
+	if err != nil {
+		code.AbortWithException(c, code.ParamError, err)
+		return
+	}
+	if data.Locale == "" {
+		code.AbortWithException(c, code.ParamError, errors.New("未指定语言"))
+		return
+	}
+	user, err := service.GetUserSession(c)
+	if err != nil {
+		code.AbortWithException(c, code.NotLogin, err)
+		return
+	}
+	survey, ok := getPermittedSurvey(c, user, data.SurveyID)
+	if !ok {
+		return
+	}
+	err = service.DeleteTranslation(survey.ID, data.Locale)
+	if err != nil {
+		code.AbortWithException(c, code.ServerError, err)
+		return
+	}
+	utils.JsonSuccessResponse(c, nil)
+}
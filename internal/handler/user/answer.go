@@ -0,0 +1,129 @@
+package user
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"QA-System/internal/dao"
+	"QA-System/internal/pkg/code"
+	"QA-System/internal/pkg/utils"
+	"QA-System/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type getMyAnswerData struct {
+	ID    int64  `form:"id" binding:"required"`
+	Token string `form:"token" binding:"required"`
+}
+
+// GetMyAnswer 答题者获取自己最近一次提交的答卷
+func GetMyAnswer(c *gin.Context) {
+	var data getMyAnswerData
+	err := c.ShouldBindQuery(&data)
+	if err != nil {
+		code.AbortWithException(c, code.ParamError, err)
+		return
+	}
+	survey, err := service.GetSurveyByID(data.ID)
+	if err != nil {
+		code.AbortWithException(c, code.ServerError, err)
+		return
+	}
+	// 仅统一验证的问卷可以根据学号找到答卷
+	if !survey.Verify {
+		code.AbortWithException(c, code.AnswerEditError, errors.New("问卷未开启统一验证"))
+		return
+	}
+	userInfo, err := utils.ParseJWT(data.Token)
+	if err != nil {
+		code.AbortWithException(c, code.ServerError, err)
+		return
+	}
+	answerSheet, err := service.GetAnswerSheetByStudentID(survey.ID, userInfo.StudentID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		code.AbortWithException(c, code.AnswerSheetNotExist, errors.New("答卷不存在"))
+		return
+	} else if err != nil {
+		code.AbortWithException(c, code.ServerError, err)
+		return
+	}
+	// 问卷修改后将答案对应到当前版本的题目
+	answerSheets, err := service.MapAnswerSheets(survey.ID, []dao.AnswerSheet{*answerSheet})
+	if err != nil {
+		code.AbortWithException(c, code.ServerError, err)
+		return
+	}
+	questionsList := make([]dao.QuestionsList, 0, len(answerSheet.Answers))
+	for _, answer := range answerSheets[0].Answers {
+		questionsList = append(questionsList, dao.QuestionsList{
+			QuestionID: answer.QuestionID,
+			Answer:     answer.Content,
+		})
+	}
+	response := getSubmitResponse(survey, answerSheet)
+	response["questions_list"] = questionsList
+	utils.JsonSuccessResponse(c, response)
+}
+
+type updateMyAnswerData struct {
+	ID            int64               `json:"id" binding:"required"`
+	Token         string              `json:"token" binding:"required"`
+	QuestionsList []dao.QuestionsList `json:"questions_list"`
+}
+
+// UpdateMyAnswer 答题者在截止前修改自己最近一次提交的答卷
+func UpdateMyAnswer(c *gin.Context) {
+	var data updateMyAnswerData
+	err := c.ShouldBindJSON(&data)
+	if err != nil {
+		code.AbortWithException(c, code.ParamError, err)
+		return
+	}
+	survey, err := service.GetSurveyByID(data.ID)
+	if err != nil {
+		code.AbortWithException(c, code.ServerError, err)
+		return
+	}
+	if !survey.Verify {
+		code.AbortWithException(c, code.AnswerEditError, errors.New("问卷未开启统一验证"))
+		return
+	}
+	userInfo, err := utils.ParseJWT(data.Token)
+	if err != nil {
+		code.AbortWithException(c, code.ServerError, err)
+		return
+	}
+	oldSheet, err := service.GetAnswerSheetByStudentID(survey.ID, userInfo.StudentID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		code.AbortWithException(c, code.AnswerSheetNotExist, errors.New("答卷不存在"))
+		return
+	} else if err != nil {
+		code.AbortWithException(c, code.ServerError, err)
+		return
+	}
+	// 按译文作答的选项还原为原文
+	data.QuestionsList, err = service.NormalizeAnswers(survey.ID, data.QuestionsList)
+	if err != nil {
+		code.AbortWithException(c, code.ServerError, err)
+		return
+	}
+	// 与提交答卷使用相同的检查，修改答卷不计入填写次数
+	questionsList, ok := checkAnswers(c, survey, data.QuestionsList)
+	if !ok {
+		return
+	}
+	answerSheet, fullQuestion, err := service.UpdateAnswerSheet(oldSheet, questionsList,
+		time.Now().Format(time.DateTime))
+	if errors.Is(err, service.ErrOptionFull) {
+		code.AbortWithQuestionException(c, code.OptionFullError, fullQuestion.ID, fullQuestion.SerialNum,
+			errors.New("问题"+strconv.Itoa(fullQuestion.SerialNum)+"选项名额已满"))
+		return
+	} else if err != nil {
+		code.AbortWithException(c, code.ServerError, err)
+		return
+	}
+	utils.JsonSuccessResponse(c, getSubmitResponse(survey, answerSheet))
+}
@@ -0,0 +1,157 @@
+package user
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"QA-System/internal/dao"
+	"QA-System/internal/model"
+	"QA-System/internal/pkg/code"
+	"QA-System/internal/pkg/utils"
+	"QA-System/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type saveDraftData struct {
+	ID            int64               `json:"id" binding:"required"`
+	Token         string              `json:"token"`
+	ResumeToken   string              `json:"resume_token"`
+	QuestionsList []dao.QuestionsList `json:"questions_list"`
+}
+
+// SaveDraft 保存答卷草稿
+func SaveDraft(c *gin.Context) {
+	var data saveDraftData
+	err := c.ShouldBindJSON(&data)
+	if err != nil {
+		code.AbortWithException(c, code.ParamError, err)
+		return
+	}
+	survey, err := service.GetSurveyByID(data.ID)
+	if err != nil {
+		code.AbortWithException(c, code.ServerError, err)
+		return
+	}
+	// 判断问卷是否开放
+	if survey.Status != 2 {
+		code.AbortWithException(c, code.SurveyNotOpen, errors.New("问卷未开放"))
+		return
+	}
+	if !survey.Deadline.IsZero() && survey.Deadline.Before(time.Now()) {
+		code.AbortWithException(c, code.TimeBeyondError, errors.New("问卷填写时间已截至"))
+		return
+	}
+	studentID, err := getDraftStudentID(survey, data.Token)
+	if err != nil {
+		code.AbortWithException(c, code.ServerError, err)
+		return
+	}
+	if studentID == "" && data.ResumeToken != "" && !service.ValidResumeToken(data.ResumeToken) {
+		code.AbortWithException(c, code.ParamError, errors.New("续填令牌格式错误"))
+		return
+	}
+	// 非统一验证的问卷首次保存时生成续填令牌
+	if studentID == "" && data.ResumeToken == "" {
+		data.ResumeToken, err = service.NewResumeToken()
+		if err != nil {
+			code.AbortWithException(c, code.ServerError, err)
+			return
+		}
+	}
+	// 草稿中的问题必须属于该问卷
+	questions, err := service.GetQuestionsBySurveyID(survey.ID)
+	if err != nil {
+		code.AbortWithException(c, code.ServerError, err)
+		return
+	}
+	if len(data.QuestionsList) > len(questions) {
+		code.AbortWithException(c, code.ParamError, errors.New("草稿中的问题数量超出问卷问题数量"))
+		return
+	}
+	questionMap := make(map[int]bool)
+	for _, question := range questions {
+		questionMap[question.ID] = true
+	}
+	// 同一问题重复填写时保留最后一次的答案
+	indexMap := make(map[int]int)
+	questionsList := make([]dao.QuestionsList, 0, len(data.QuestionsList))
+	for _, q := range data.QuestionsList {
+		if !questionMap[q.QuestionID] {
+			code.AbortWithException(c, code.ParamError, errors.New("问题"+strconv.Itoa(q.QuestionID)+"不属于该问卷"))
+			return
+		}
+		if i, ok := indexMap[q.QuestionID]; ok {
+			questionsList[i] = q
+			continue
+		}
+		indexMap[q.QuestionID] = len(questionsList)
+		questionsList = append(questionsList, q)
+	}
+	saveTime, err := service.SaveDraft(survey, studentID, data.ResumeToken, questionsList)
+	if errors.Is(err, service.ErrDraftTooLarge) {
+		code.AbortWithException(c, code.ParamError, err)
+		return
+	} else if err != nil {
+		code.AbortWithException(c, code.ServerError, err)
+		return
+	}
+	response := gin.H{
+		"time": saveTime,
+	}
+	if studentID == "" {
+		response["resume_token"] = data.ResumeToken
+	}
+	utils.JsonSuccessResponse(c, response)
+}
+
+type getDraftData struct {
+	ID          int64  `form:"id" binding:"required"`
+	Token       string `form:"token"`
+	ResumeToken string `form:"resume_token"`
+}
+
+// GetDraft 获取答卷草稿
+func GetDraft(c *gin.Context) {
+	var data getDraftData
+	err := c.ShouldBindQuery(&data)
+	if err != nil {
+		code.AbortWithException(c, code.ParamError, err)
+		return
+	}
+	survey, err := service.GetSurveyByID(data.ID)
+	if err != nil {
+		code.AbortWithException(c, code.ServerError, err)
+		return
+	}
+	studentID, err := getDraftStudentID(survey, data.Token)
+	if err != nil {
+		code.AbortWithException(c, code.ServerError, err)
+		return
+	}
+	if studentID == "" && !service.ValidResumeToken(data.ResumeToken) {
+		code.AbortWithException(c, code.ParamError, errors.New("续填令牌格式错误"))
+		return
+	}
+	draft, err := service.GetDraft(survey.ID, studentID, data.ResumeToken)
+	if err != nil {
+		code.AbortWithException(c, code.ServerError, err)
+		return
+	}
+	utils.JsonSuccessResponse(c, gin.H{
+		"draft": draft,
+	})
+}
+
+// getDraftStudentID 获取统一验证问卷答题者的学号，非统一验证的问卷返回空字符串
+func getDraftStudentID(survey *model.Survey, token string) (string, error) {
+	if !survey.Verify {
+		return "", nil
+	}
+	userInfo, err := utils.ParseJWT(token)
+	if err != nil {
+		return "", err
+	}
+	return userInfo.StudentID, nil
+}
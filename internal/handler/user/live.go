@@ -0,0 +1,89 @@
+package user
+
+import (
+	"errors"
+	"io"
+	"time"
+
+	"QA-System/internal/pkg/code"
+	"QA-System/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// liveHeartbeat 实时结果连接的心跳间隔，避免空闲连接被代理断开
+const liveHeartbeat = 30 * time.Second
+
+// GetLiveStatistics 通过 SSE 推送投票的实时结果
+// 连接建立后先推送 snapshot 事件作为完整结果，之后每有答卷变化推送 update 事件，客户端按变化累加
+// snapshot 和 update 均带有序号，序号不大于 snapshot 序号的变化已计入完整结果，不再推送，客户端也应丢弃
+func GetLiveStatistics(c *gin.Context) {
+	var data getSurveyData
+	err := c.ShouldBindQuery(&data)
+	if err != nil {
+		code.AbortWithException(c, code.ParamError, err)
+		return
+	}
+	survey, err := service.GetSurveyByID(data.ID)
+	if err != nil {
+		code.AbortWithException(c, code.ServerError, err)
+		return
+	}
+	if survey.Type != 1 {
+		code.AbortWithException(c, code.SurveyTypeError, errors.New("问卷为调研问卷"))
+		return
+	}
+	if err := service.CheckResultVisibility(survey, data.Token); errors.Is(err, service.ErrLiveResultsHidden) {
+		code.AbortWithException(c, code.LiveResultHiddenError, err)
+		return
+	} else if errors.Is(err, service.ErrResultsHidden) {
+		code.AbortWithException(c, code.ResultHiddenError, err)
+		return
+	} else if err != nil {
+		code.AbortWithException(c, code.ServerError, err)
+		return
+	}
+	// 先订阅再获取序号和完整结果，避免遗漏两者之间的答卷
+	updates, cancel := service.SubscribeLiveResults(survey.ID)
+	defer cancel()
+	seq, err := service.GetLiveSequence(survey.ID)
+	if err != nil {
+		code.AbortWithException(c, code.ServerError, err)
+		return
+	}
+	statistics, err := service.GetSurveyStatistics(survey.ID)
+	if err != nil {
+		code.AbortWithException(c, code.ServerError, err)
+		return
+	}
+	total, err := service.CountAnswerSheets(survey.ID)
+	if err != nil {
+		code.AbortWithException(c, code.ServerError, err)
+		return
+	}
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.SSEvent("snapshot", gin.H{"statistics": statistics, "total": total, "seq": seq})
+	c.Writer.Flush()
+
+	ticker := time.NewTicker(liveHeartbeat)
+	defer ticker.Stop()
+	c.Stream(func(_ io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case update, ok := <-updates:
+			if !ok {
+				return false
+			}
+			if update.Seq <= seq {
+				return true
+			}
+			c.SSEvent("update", update)
+			return true
+		case <-ticker.C:
+			c.SSEvent("ping", time.Now().Unix())
+			return true
+		}
+	})
+}
@@ -6,7 +6,6 @@ import (
 	"errors"
 	"mime/multipart"
 	"net/http"
-	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -30,6 +29,7 @@ import (
 type submitSurveyData struct {
 	ID            int64               `json:"id" binding:"required"`
 	Token         string              `json:"token"`
+	ResumeToken   string              `json:"resume_token"` // 续填令牌，提交后删除对应草稿
 	QuestionsList []dao.QuestionsList `json:"questions_list"`
 }
 
@@ -56,60 +56,17 @@ func SubmitSurvey(c *gin.Context) {
 		}
 	}
 	stuId := userInfo.StudentID
-	questions, err := service.GetQuestionsBySurveyID(survey.ID)
+	// 按译文作答的选项还原为原文
+	data.QuestionsList, err = service.NormalizeAnswers(survey.ID, data.QuestionsList)
 	if err != nil {
 		code.AbortWithException(c, code.ServerError, err)
 		return
 	}
-	if len(questions) != len(data.QuestionsList) {
-		code.AbortWithException(c, code.SurveyError, errors.New("问卷问题和上传问题数量不一致"))
-		return
-	}
-	// 判断填写时间是否在问卷有效期内
-	if !survey.Deadline.IsZero() && survey.Deadline.Before(time.Now()) {
-		code.AbortWithException(c, code.TimeBeyondError, errors.New("填写时间已过"))
-		return
-	}
-	if !survey.StartTime.IsZero() && survey.StartTime.After(time.Now()) {
-		code.AbortWithException(c, code.TimeBeyondError, errors.New("填写时间未到"))
-		return
-	}
-	// 判断问卷是否开放
-	if survey.Status != 2 {
-		code.AbortWithException(c, code.SurveyNotOpen, errors.New("问卷未开放"))
+	questionsList, ok := checkAnswers(c, survey, data.QuestionsList)
+	if !ok {
 		return
 	}
-	// 逐个判断问题答案
-	for _, q := range data.QuestionsList {
-		question, err := service.GetQuestionByID(q.QuestionID)
-		if err != nil {
-			code.AbortWithException(c, code.ServerError, err)
-			return
-		}
-		if question.SurveyID != survey.ID {
-			code.AbortWithException(c, code.ServerError,
-				errors.New("问题"+strconv.Itoa(question.SerialNum)+"不属于该问卷"))
-			return
-		}
-		// 判断必填字段是否为空
-		if question.Required && q.Answer == "" {
-			code.AbortWithException(c, code.ServerError,
-				errors.New("问题"+strconv.Itoa(q.QuestionID)+"必填字段为空"))
-			return
-		}
-		// 判断多选题选项数量是否符合要求
-		if (question.QuestionType == 2 && survey.Type == 0) || (question.QuestionType == 1 && survey.Type == 1) {
-			length := uint(len(strings.Split(q.Answer, "┋")))
-			if question.MinimumOption != 0 && length < question.MinimumOption {
-				code.AbortWithException(c, code.OptionNumError, errors.New("问题"+strconv.Itoa(q.QuestionID)+"选项数量不符合要求"))
-				return
-			}
-			if question.MaximumOption != 0 && length > question.MaximumOption {
-				code.AbortWithException(c, code.OptionNumError, errors.New("问题"+strconv.Itoa(q.QuestionID)+"选项数量不符合要求"))
-				return
-			}
-		}
-	}
+	data.QuestionsList = questionsList
 	flagSum, flagDay := false, false
 
 	if survey.Verify {
@@ -138,9 +95,43 @@ func SubmitSurvey(c *gin.Context) {
 		}
 	}
 
+	// 占用答题者所属群体的配额
+	var reservedQuota []string
+	if survey.Verify {
+		var fullQuota *model.Quota
+		reservedQuota, fullQuota, err = service.ReserveQuota(survey.ID, userInfo)
+		if errors.Is(err, service.ErrQuotaFull) {
+			code.AbortWithException(c, code.QuotaFullError, errors.New(fullQuota.Field+":"+fullQuota.Value+"配额已满"))
+			return
+		} else if err != nil {
+			code.AbortWithException(c, code.ServerError, err)
+			return
+		}
+	}
+	// 占用限额选项的名额
+	reserved, fullQuestion, err := service.ReserveOptionCapacity(survey.ID, data.QuestionsList)
+	if err != nil {
+		if releaseErr := service.ReleaseQuota(reservedQuota); releaseErr != nil {
+			zap.L().Error("释放配额失败", zap.Int64("survey_id", survey.ID), zap.Error(releaseErr))
+		}
+		if errors.Is(err, service.ErrOptionFull) {
+			code.AbortWithQuestionException(c, code.OptionFullError, fullQuestion.ID, fullQuestion.SerialNum,
+				errors.New("问题"+strconv.Itoa(fullQuestion.SerialNum)+"选项名额已满"))
+			return
+		}
+		code.AbortWithException(c, code.ServerError, err)
+		return
+	}
+
 	submitTime := time.Now().Format(time.DateTime)
-	err = service.SubmitSurvey(data.ID, data.QuestionsList, submitTime)
+	answerSheet, err := service.SubmitSurvey(data.ID, data.QuestionsList, submitTime)
 	if err != nil {
+		if releaseErr := service.ReleaseOptionCapacity(reserved); releaseErr != nil {
+			zap.L().Error("释放选项名额失败", zap.Int64("survey_id", survey.ID), zap.Error(releaseErr))
+		}
+		if releaseErr := service.ReleaseQuota(reservedQuota); releaseErr != nil {
+			zap.L().Error("释放配额失败", zap.Int64("survey_id", survey.ID), zap.Error(releaseErr))
+		}
 		code.AbortWithException(c, code.ServerError, err)
 		return
 	}
@@ -161,18 +152,22 @@ func SubmitSurvey(c *gin.Context) {
 			}
 		}
 		// 记录授权
-		if err = service.CreateOauthRecord(userInfo, time.Now(), data.ID); err != nil {
+		if err = service.CreateOauthRecord(userInfo, time.Now(), data.ID, answerSheet.AnswerID); err != nil {
 			code.AbortWithException(c, code.ServerError, err)
 			return
 		}
 	}
-	utils.JsonSuccessResponse(c, gin.H{
-		"time": submitTime,
-	})
+	// 提交后删除草稿
+	if err := service.DeleteDraft(survey.ID, stuId, data.ResumeToken); err != nil {
+		zap.L().Warn("删除草稿失败", zap.Int64("survey_id", survey.ID), zap.Error(err))
+	}
+	utils.JsonSuccessResponse(c, getSubmitResponse(survey, answerSheet))
 }
 
 type getSurveyData struct {
-	ID int64 `form:"id" binding:"required"`
+	ID    int64  `form:"id" binding:"required"`
+	Token string `form:"token"`
+	Lang  string `form:"lang"` // 问卷语言 为空时根据 Accept-Language 选择
 }
 
 // GetSurvey 用户获取问卷
@@ -203,12 +198,45 @@ func GetSurvey(c *gin.Context) {
 		code.AbortWithException(c, code.SurveyNotOpen, errors.New("问卷未开放"))
 		return
 	}
+	// 统一验证的问卷根据学号确定乱序种子，并用于替换身份信息占位符
+	studentID := ""
+	var userInfo *oauth.UserInfo
+	if data.Token != "" {
+		if info, err := utils.ParseJWT(data.Token); err == nil {
+			studentID = info.StudentID
+			userInfo = &info
+		}
+	}
 	// 获取相应的问题
 	questions, err := service.GetQuestionsBySurveyID(survey.ID)
 	if err != nil {
 		code.AbortWithException(c, code.ServerError, err)
 		return
 	}
+	// 获取问卷分页
+	sections, err := service.GetSectionsBySurveyID(survey.ID)
+	if err != nil {
+		code.AbortWithException(c, code.ServerError, err)
+		return
+	}
+	// 按答题者固定的顺序打乱题目
+	seed := service.GetRespondentSeed(studentID, c.ClientIP())
+	questions = service.ArrangeQuestions(survey, sections, questions, seed)
+	// 选择问卷语言
+	locales, err := service.GetTranslationLocales(survey.ID)
+	if err != nil {
+		code.AbortWithException(c, code.ServerError, err)
+		return
+	}
+	locale := service.MatchLocale(locales, data.Lang, c.GetHeader("Accept-Language"))
+	translator, err := service.GetTranslator(survey.ID, locale)
+	if err != nil {
+		code.AbortWithException(c, code.ServerError, err)
+		return
+	}
+	translator.TranslateSurvey(survey)
+	resolver := service.NewPlaceholderResolver(userInfo, questions)
+	surveyPlaceholders := resolver.ResolveAll(&survey.Title, &survey.Desc)
 	// 构建问卷响应
 	questionListsResponse := make([]map[string]any, 0)
 	for _, question := range questions {
@@ -217,56 +245,97 @@ func GetSurvey(c *gin.Context) {
 			code.AbortWithException(c, code.ServerError, err)
 			return
 		}
+		options = service.ArrangeOptions(question, options, seed)
+		remaining, err := service.GetOptionRemaining(survey.ID, question, options)
+		if err != nil {
+			code.AbortWithException(c, code.ServerError, err)
+			return
+		}
+		translator.TranslateOptions(question, options)
+		translator.TranslateQuestion(&question)
+		placeholders := resolver.ResolveAll(&question.Subject, &question.Description)
 		optionsResponse := make([]map[string]any, 0)
+		columnsResponse := make([]map[string]any, 0)
 		for _, option := range options {
 			optionResponse := map[string]any{
 				"img":         option.Img,
 				"content":     option.Content,
 				"description": option.Description,
 				"serial_num":  option.SerialNum,
+				"capacity":    option.Capacity,
+			}
+			// 限额选项返回剩余名额
+			if left, ok := remaining[option.ID]; ok {
+				optionResponse["remaining"] = left
+			}
+			if option.IsColumn {
+				columnsResponse = append(columnsResponse, optionResponse)
+				continue
 			}
 			optionsResponse = append(optionsResponse, optionResponse)
 		}
 
 		questionSettingResponse := map[string]any{
-			"required":       question.Required,
-			"unique":         question.Unique,
-			"other_option":   question.OtherOption,
-			"question_type":  question.QuestionType,
-			"reg":            question.Reg,
-			"maximum_option": question.MaximumOption,
-			"minimum_option": question.MinimumOption,
+			"required":        question.Required,
+			"unique":          question.Unique,
+			"other_option":    question.OtherOption,
+			"question_type":   question.QuestionType,
+			"reg":             question.Reg,
+			"shuffle_options": question.ShuffleOptions,
+			"input_type":      question.InputType,
+			"maximum_option":  question.MaximumOption,
+			"minimum_option":  question.MinimumOption,
+			"scale_type":      question.ScaleType,
+			"min_value":       question.MinValue,
+			"max_value":       question.MaxValue,
+			"step":            question.Step,
 		}
 
 		questionListMap := map[string]any{
 			"id":           question.ID,
 			"serial_num":   question.SerialNum,
+			"section_num":  question.SectionNum,
 			"subject":      question.Subject,
 			"description":  question.Description,
 			"img":          question.Img,
 			"ques_setting": questionSettingResponse,
 			"options":      optionsResponse,
+			"columns":      columnsResponse,
+			"placeholders": placeholders,
 		}
 		questionListsResponse = append(questionListsResponse, questionListMap)
 	}
 
+	// 获取题目显示逻辑
+	rules, err := service.GetRulesBySurveyID(survey.ID)
+	if err != nil {
+		code.AbortWithException(c, code.ServerError, err)
+		return
+	}
+
 	questionsConfigResponse := map[string]any{
 		"title":         survey.Title,
 		"desc":          survey.Desc,
 		"question_list": questionListsResponse,
+		"logic":         service.GetLogicResponse(rules),
+		"sections":      service.GetSectionResponse(sections, questions),
+		"placeholders":  surveyPlaceholders,
 	}
 	baseConfigResponse := map[string]any{
-		"start_time":     survey.StartTime,
-		"end_time":       survey.Deadline,
-		"day_limit":      survey.DailyLimit,
-		"sum_limit":      survey.SumLimit,
-		"verify":         survey.Verify,
-		"undergrad_only": survey.UndergradOnly,
+		"start_time":        survey.StartTime,
+		"end_time":          survey.Deadline,
+		"day_limit":         survey.DailyLimit,
+		"sum_limit":         survey.SumLimit,
+		"verify":            survey.Verify,
+		"undergrad_only":    survey.UndergradOnly,
+		"result_visibility": survey.ResultVisibility,
 	}
 	response := map[string]any{
 		"id":          survey.ID,
 		"status":      survey.Status,
 		"survey_type": survey.Type,
+		"locale":      locale,
+		"locales":     locales,
 		"base_config": baseConfigResponse,
 		"ques_config": questionsConfigResponse,
 	}
@@ -429,20 +498,6 @@ func Oauth(c *gin.Context) {
 	})
 }
 
-type getOptionCount struct {
-	SerialNum int    `json:"serial_num"` // 选项序号
-	Content   string `json:"content"`    // 选项内容
-	Count     int    `json:"count"`      // 选项数量
-	Rank      int    `json:"rank"`       // 选项排名
-}
-
-type getSurveyStatisticsResponse struct {
-	SerialNum    int              `json:"serial_num"`    // 问题序号
-	Question     string           `json:"question"`      // 问题内容
-	QuestionType int              `json:"question_type"` // 问题类型  1:单选 2:多选
-	Options      []getOptionCount `json:"options"`       // 选项内容
-}
-
 // GetSurveyStatistics 获取投票统计
 func GetSurveyStatistics(c *gin.Context) {
 	var data getSurveyData
@@ -460,189 +515,185 @@ func GetSurveyStatistics(c *gin.Context) {
 		code.AbortWithException(c, code.SurveyTypeError, errors.New("问卷为调研问卷"))
 		return
 	}
-	answerSheets, err := service.GetSurveyAnswersBySurveyID(data.ID)
-	if err != nil {
+	if err := service.CheckResultVisibility(survey, data.Token); errors.Is(err, service.ErrLiveResultsHidden) {
+		code.AbortWithException(c, code.LiveResultHiddenError, err)
+		return
+	} else if errors.Is(err, service.ErrResultsHidden) {
+		code.AbortWithException(c, code.ResultHiddenError, err)
+		return
+	} else if err != nil {
 		code.AbortWithException(c, code.ServerError, err)
 		return
 	}
-
-	questions, err := service.GetQuestionsBySurveyID(data.ID)
+	statistics, err := service.GetSurveyStatistics(survey.ID)
 	if err != nil {
 		code.AbortWithException(c, code.ServerError, err)
 		return
 	}
+	utils.JsonSuccessResponse(c, gin.H{"statistics": statistics})
+}
 
-	// 如果 answerSheets 为空，则返回所有问题和选项统计为 0
-	if len(answerSheets) == 0 {
-		response := make([]getSurveyStatisticsResponse, 0, len(questions))
-		for _, q := range questions {
-			options, err := service.GetOptionsByQuestionID(q.ID)
-			if err != nil {
-				code.AbortWithException(c, code.ServerError, err)
-				return
-			}
-
-			qOptions := make([]getOptionCount, 0, len(options)+1)
-			for _, option := range options {
-				qOptions = append(qOptions, getOptionCount{
-					SerialNum: option.SerialNum,
-					Content:   option.Content,
-					Count:     0,
-					Rank:      1,
-				})
-			}
-
-			// 如果支持 "其他" 选项，添加一项
-			if q.OtherOption {
-				qOptions = append(qOptions, getOptionCount{
-					SerialNum: 0,
-					Content:   "其他",
-					Count:     0,
-					Rank:      1,
-				})
-			}
-
-			response = append(response, getSurveyStatisticsResponse{
-				SerialNum:    q.SerialNum,
-				Question:     q.Subject,
-				QuestionType: q.QuestionType,
-				Options:      qOptions,
-			})
+// checkAnswers 检查答卷是否符合问卷的显示逻辑、填写时间和各题目要求
+// 返回去除隐藏题目后的答案，检查不通过时已中止请求并返回 false
+func checkAnswers(c *gin.Context, survey *model.Survey, questionsList []dao.QuestionsList) ([]dao.QuestionsList, bool) {
+	questions, err := service.GetQuestionsBySurveyID(survey.ID)
+	if err != nil {
+		code.AbortWithException(c, code.ServerError, err)
+		return nil, false
+	}
+	// 根据显示逻辑计算可见题目
+	rules, err := service.GetRulesBySurveyID(survey.ID)
+	if err != nil {
+		code.AbortWithException(c, code.ServerError, err)
+		return nil, false
+	}
+	// 问卷修改后旧版本的题目不再接受作答
+	currentMap := make(map[int]bool)
+	for _, question := range questions {
+		currentMap[question.ID] = true
+	}
+	answerMap := make(map[int]string)
+	for _, q := range questionsList {
+		if !currentMap[q.QuestionID] {
+			code.AbortWithException(c, code.SurveyVersionError,
+				errors.New("问题"+strconv.Itoa(q.QuestionID)+"不属于当前版本的问卷"))
+			return nil, false
 		}
-		utils.JsonSuccessResponse(c, gin.H{"statistics": response})
-		return
+		if _, ok := answerMap[q.QuestionID]; ok {
+			code.AbortWithException(c, code.SurveyError, errors.New("问题"+strconv.Itoa(q.QuestionID)+"重复提交"))
+			return nil, false
+		}
+		answerMap[q.QuestionID] = q.Answer
+	}
+	visible, err := service.GetVisibleQuestions(questions, rules, answerMap)
+	if err != nil {
+		code.AbortWithException(c, code.ServerError, err)
+		return nil, false
 	}
-
-	// 问题编号对应的问题
-	questionMap := make(map[int]model.Question)
-	// 问题编号对应的选项们
-	optionsMap := make(map[int][]model.Option)
-	// 问题编号与选项内容对应的选项
-	optionAnswerMap := make(map[int]map[string]model.Option)
-	// 问题编号与选项序号对应的选项
-	optionSerialNumMap := make(map[int]map[int]model.Option)
 	for _, question := range questions {
-		questionMap[question.ID] = question
-		optionAnswerMap[question.ID] = make(map[string]model.Option)
-		optionSerialNumMap[question.ID] = make(map[int]model.Option)
-		options, err := service.GetOptionsByQuestionID(question.ID)
+		if _, ok := answerMap[question.ID]; visible[question.ID] && !ok {
+			code.AbortWithException(c, code.SurveyError, errors.New("问卷问题和上传问题不一致"))
+			return nil, false
+		}
+	}
+	// 被隐藏的题目不接受作答
+	visibleList := make([]dao.QuestionsList, 0, len(questionsList))
+	for _, q := range questionsList {
+		if visible[q.QuestionID] {
+			visibleList = append(visibleList, q)
+			continue
+		}
+		if q.Answer != "" {
+			code.AbortWithException(c, code.QuestionHiddenError,
+				errors.New("问题"+strconv.Itoa(q.QuestionID)+"已被隐藏"))
+			return nil, false
+		}
+	}
+	// 判断填写时间是否在问卷有效期内
+	if !survey.Deadline.IsZero() && survey.Deadline.Before(time.Now()) {
+		code.AbortWithException(c, code.TimeBeyondError, errors.New("填写时间已过"))
+		return nil, false
+	}
+	if !survey.StartTime.IsZero() && survey.StartTime.After(time.Now()) {
+		code.AbortWithException(c, code.TimeBeyondError, errors.New("填写时间未到"))
+		return nil, false
+	}
+	// 判断问卷是否开放
+	if survey.Status != 2 {
+		code.AbortWithException(c, code.SurveyNotOpen, errors.New("问卷未开放"))
+		return nil, false
+	}
+	// 逐个判断问题答案
+	for _, q := range visibleList {
+		question, err := service.GetQuestionByID(q.QuestionID)
 		if err != nil {
 			code.AbortWithException(c, code.ServerError, err)
-			return
+			return nil, false
 		}
-		optionsMap[question.ID] = options
-		for _, option := range options {
-			optionAnswerMap[question.ID][option.Content] = option
-			optionSerialNumMap[question.ID][option.SerialNum] = option
-		}
-	}
-
-	// 问题编号对应的选项编号对应的选项数量
-	optionCounts := make(map[int]map[int]int)
-	for _, sheet := range answerSheets {
-		for _, answer := range sheet.Answers {
-			options := optionsMap[answer.QuestionID]
-			question := questionMap[answer.QuestionID]
-			// 初始化选项统计（确保每个选项的计数存在且为 0）
-			if _, initialized := optionCounts[question.ID]; !initialized {
-				counts := ensureMap(optionCounts, question.ID)
-				for _, option := range options {
-					counts[option.SerialNum] = 0
+		if question.SurveyID != survey.ID {
+			code.AbortWithException(c, code.ServerError,
+				errors.New("问题"+strconv.Itoa(question.SerialNum)+"不属于该问卷"))
+			return nil, false
+		}
+		// 判断必填字段是否为空
+		if question.Required && q.Answer == "" {
+			code.AbortWithException(c, code.ServerError,
+				errors.New("问题"+strconv.Itoa(q.QuestionID)+"必填字段为空"))
+			return nil, false
+		}
+		// 判断选择题选项是否重复
+		if (question.QuestionType == 1 || question.QuestionType == 2) && q.Answer != "" {
+			selected := make(map[string]bool)
+			for _, answer := range strings.Split(q.Answer, "┋") {
+				if selected[answer] {
+					code.AbortWithQuestionException(c, code.OptionRepeatError, question.ID, question.SerialNum,
+						errors.New("问题"+strconv.Itoa(question.SerialNum)+"选项"+answer+"重复选择"))
+					return nil, false
 				}
+				selected[answer] = true
 			}
-			if question.QuestionType == 1 {
-				answerOptions := strings.Split(answer.Content, "┋")
-				questionOptions := optionAnswerMap[answer.QuestionID]
-				for _, answerOption := range answerOptions {
-					// 查找选项
-					if questionOptions != nil {
-						option, exists := questionOptions[answerOption]
-						if exists {
-							// 如果找到选项，处理逻辑
-							ensureMap(optionCounts, answer.QuestionID)[option.SerialNum]++
-							continue
-						}
-					}
-					// 如果选项不存在，处理为 "其他" 选项
-					ensureMap(optionCounts, answer.QuestionID)[0]++
-				}
+		}
+		// 判断多选题选项数量是否符合要求
+		if (question.QuestionType == 2 && survey.Type != 1) || (question.QuestionType == 1 && survey.Type == 1) {
+			length := uint(len(strings.Split(q.Answer, "┋")))
+			if question.MinimumOption != 0 && length < question.MinimumOption {
+				code.AbortWithException(c, code.OptionNumError, errors.New("问题"+strconv.Itoa(q.QuestionID)+"选项数量不符合要求"))
+				return nil, false
+			}
+			if question.MaximumOption != 0 && length > question.MaximumOption {
+				code.AbortWithException(c, code.OptionNumError, errors.New("问题"+strconv.Itoa(q.QuestionID)+"选项数量不符合要求"))
+				return nil, false
 			}
 		}
-	}
-
-	response := make([]getSurveyStatisticsResponse, 0, len(optionCounts))
-	for qid, options := range optionCounts {
-		q := questionMap[qid]
-		var qOptions []getOptionCount
-		if q.OtherOption {
-			qOptions = make([]getOptionCount, 0, len(options)+1)
-			// 添加其他选项
-			qOptions = append(qOptions, getOptionCount{
-				SerialNum: 0,
-				Content:   "其他",
-				Count:     options[0],
-			})
-		} else {
-			qOptions = make([]getOptionCount, 0, len(options))
-		}
-		// 按序号排序
-		sortedSerialNums := make([]int, 0, len(options))
-		for oSerialNum := range options {
-			sortedSerialNums = append(sortedSerialNums, oSerialNum)
-		}
-		sort.Ints(sortedSerialNums)
-		for _, oSerialNum := range sortedSerialNums {
-			count := options[oSerialNum]
-			op := optionSerialNumMap[qid][oSerialNum]
-			qOptions = append(qOptions, getOptionCount{
-				SerialNum: op.SerialNum,
-				Content:   op.Content,
-				Count:     count,
-			})
+		// 判断矩阵题答案是否符合要求
+		if question.QuestionType == 7 {
+			if err := service.CheckMatrixAnswer(question, q.Answer); err != nil {
+				code.AbortWithQuestionException(c, code.MatrixAnswerError, question.ID, question.SerialNum, err)
+				return nil, false
+			}
 		}
-
-		// 创建一个副本用于排序
-		sortedQOptions := make([]getOptionCount, len(qOptions))
-		copy(sortedQOptions, qOptions)
-
-		// 按选项数量排序
-		sort.Slice(sortedQOptions, func(i, j int) bool {
-			// 按数量降序排列，数量相同时按序号升序排列
-			if sortedQOptions[i].Count == sortedQOptions[j].Count {
-				return sortedQOptions[i].SerialNum < sortedQOptions[j].SerialNum
+		// 判断排序题答案是否完整
+		if question.QuestionType == 9 && q.Answer != "" {
+			if err := service.CheckRankingAnswer(question, q.Answer); err != nil {
+				code.AbortWithQuestionException(c, code.RankingAnswerError, question.ID, question.SerialNum, err)
+				return nil, false
 			}
-			return sortedQOptions[i].Count > sortedQOptions[j].Count
-		})
-
-		// 补充 rank
-		rankMap := make(map[int]int) // 用于记录选项的排名
-		currentRank := 1
-		for i := 0; i < len(sortedQOptions); i++ {
-			if i > 0 && sortedQOptions[i].Count < sortedQOptions[i-1].Count {
-				// 当前排名等于前面所有项目数量
-				currentRank = i + 1
+		}
+		// 判断填空题和简答题答案格式是否符合要求
+		if question.QuestionType == 3 || question.QuestionType == 4 {
+			if apiErr := service.CheckInputAnswer(question, q.Answer); apiErr != nil {
+				code.AbortWithQuestionException(c, apiErr, question.ID, question.SerialNum,
+					errors.New("问题"+strconv.Itoa(question.SerialNum)+"答案格式不符合要求"))
+				return nil, false
 			}
-			rankMap[sortedQOptions[i].SerialNum] = currentRank
 		}
-
-		// 将排名写回原始的 qOptions
-		for i := range qOptions {
-			qOptions[i].Rank = rankMap[qOptions[i].SerialNum]
+		// 判断量表题答案是否在范围内
+		if question.QuestionType == 8 && q.Answer != "" {
+			if err := service.CheckScaleAnswer(question, q.Answer); err != nil {
+				code.AbortWithQuestionException(c, code.ScaleAnswerError, question.ID, question.SerialNum, err)
+				return nil, false
+			}
 		}
-
-		response = append(response, getSurveyStatisticsResponse{
-			SerialNum:    q.SerialNum,
-			Question:     q.Subject,
-			QuestionType: q.QuestionType,
-			Options:      qOptions,
-		})
 	}
-	utils.JsonSuccessResponse(c, gin.H{"statistics": response})
+	return visibleList, true
 }
 
-func ensureMap(m map[int]map[int]int, key int) map[int]int {
-	if m[key] == nil {
-		m[key] = make(map[int]int)
+// getSubmitResponse 构建提交答卷的响应，测验按设置返回得分
+func getSubmitResponse(survey *model.Survey, answerSheet *dao.AnswerSheet) gin.H {
+	response := gin.H{
+		"time":      answerSheet.Time,
+		"answer_id": answerSheet.AnswerID.Hex(),
+	}
+	if survey.Type == 2 && survey.ShowScore {
+		scores := make([]gin.H, 0, len(answerSheet.Answers))
+		for _, answer := range answerSheet.Answers {
+			scores = append(scores, gin.H{
+				"question_id": answer.QuestionID,
+				"score":       answer.Score,
+			})
+		}
+		response["score"] = answerSheet.Score
+		response["scores"] = scores
 	}
-	return m[key]
+	return response
 }
@@ -22,6 +22,16 @@ func ErrHandler() gin.HandlerFunc {
 		if len(c.Errors) > 0 {
 			err := c.Errors.Last().Err
 			if err != nil {
+				// 与具体题目相关的错误附带题目信息返回
+				var questionErr *code.QuestionError
+				if errors.As(err, &questionErr) {
+					utils.JsonResponse(c, http.StatusOK, questionErr.Err.Code, questionErr.Err.Msg, gin.H{
+						"question_id": questionErr.QuestionID,
+						"serial_num":  questionErr.SerialNum,
+					})
+					return
+				}
+
 				var apiErr *code.Error
 
 				// 尝试将错误转换为 apiException
@@ -2,10 +2,14 @@ package model
 
 // Option 选项模型
 type Option struct {
-	ID          int    `json:"id"`          // 选项ID
-	QuestionID  int    `json:"question_id"` // 问题ID
-	SerialNum   int    `json:"serial_num"`  // 选项序号
-	Content     string `json:"content"`     // 选项内容
-	Description string `json:"description"` // 选项描述
-	Img         string `json:"img"`         // 选项图片
+	ID          int     `json:"id"`          // 选项ID
+	QuestionID  int     `json:"question_id"` // 问题ID
+	SerialNum   int     `json:"serial_num"`  // 选项序号
+	Content     string  `json:"content"`     // 选项内容
+	Description string  `json:"description"` // 选项描述
+	Img         string  `json:"img"`         // 选项图片
+	IsColumn    bool    `json:"is_column"`   // 是否为矩阵题的列
+	Capacity    int     `json:"capacity"`    // 选项名额 0为不限制
+	IsCorrect   bool    `json:"is_correct"`  // 测验中是否为正确选项
+	Score       float64 `json:"score"`       // 测验中选择该选项的得分，可为负
 }
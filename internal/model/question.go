@@ -2,17 +2,28 @@ package model
 
 // Question 问题模型
 type Question struct {
-	ID            int    `json:"id"`
-	SurveyID      int64  `json:"survey_id"`      // 问卷ID
-	SerialNum     int    `json:"serial_num"`     // 题目序号
-	Img           string `json:"img"`            // 图片
-	Subject       string `json:"subject"`        // 题目
-	Description   string `json:"description"`    // 题目描述
-	Required      bool   `json:"required"`       // 是否必填
-	Unique        bool   `json:"unique"`         // 是否唯一
-	OtherOption   bool   `json:"other_option"`   // 是否有其他选项
-	QuestionType  int    `json:"question_type"`  // 题目类型 调研问卷为 1:单选(投票问卷为1投票) 2:多选 3:填空 4:简答 5:图片 6: 文件。
-	MaximumOption uint   `json:"maximum_option"` // 多选最多所选选项数 0为不限制
-	MinimumOption uint   `json:"minimum_option"` // 多选最少所选选项数 0为不限制
-	Reg           string `json:"reg"`            // 正则表达式
+	ID             int     `json:"id"`
+	SurveyID       int64   `json:"survey_id"`       // 问卷ID
+	Version        int     `json:"version"`         // 所属问卷版本
+	Key            string  `json:"key"`             // 题目标识 在问卷各版本间保持不变
+	SerialNum      int     `json:"serial_num"`      // 题目序号
+	SectionNum     int     `json:"section_num"`     // 所属分页序号 0为不分页
+	Img            string  `json:"img"`             // 图片
+	Subject        string  `json:"subject"`         // 题目
+	Description    string  `json:"description"`     // 题目描述
+	Required       bool    `json:"required"`        // 是否必填
+	Unique         bool    `json:"unique"`          // 是否唯一
+	OtherOption    bool    `json:"other_option"`    // 是否有其他选项
+	QuestionType   int     `json:"question_type"`   // 题目类型 调研问卷为 1:单选(投票问卷为1投票) 2:多选 3:填空 4:简答 5:图片 6: 文件 7:矩阵 8:量表 9:排序。
+	MaximumOption  uint    `json:"maximum_option"`  // 多选最多所选选项数 0为不限制
+	MinimumOption  uint    `json:"minimum_option"`  // 多选最少所选选项数 0为不限制
+	Reg            string  `json:"reg"`             // 正则表达式
+	ShuffleOptions bool    `json:"shuffle_options"` // 是否打乱选项顺序
+	InputType      int     `json:"input_type"`      // 填空题输入类型 0:不限 1:日期 2:日期时间 3:整数 4:小数 5:邮箱 6:手机号 7:学号
+	ScaleType      int     `json:"scale_type"`      // 量表类型 1:评分 2:NPS 3:滑块
+	MinValue       float64 `json:"min_value"`       // 最小值
+	MaxValue       float64 `json:"max_value"`       // 最大值
+	Step           float64 `json:"step"`            // 步长
+	Score          float64 `json:"score"`           // 测验题目分值
+	AnswerKey      string  `json:"answer_key"`      // 填空题标准答案，多个可接受答案用┋分隔
 }
@@ -0,0 +1,10 @@
+package model
+
+// Quota 问卷配额模型
+type Quota struct {
+	ID       int    `json:"id"`
+	SurveyID int64  `json:"survey_id"` // 问卷ID
+	Field    string `json:"field"`     // 配额字段 college:学院 gender:性别 user_type:用户类型 user_type_desc:用户类型描述
+	Value    string `json:"value"`     // 配额字段取值
+	Limit    int    `json:"limit"`     // 配额上限
+}
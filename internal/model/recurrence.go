@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// Recurrence 周期问卷规则模型，按周期以问卷为模板生成新的问卷实例
+type Recurrence struct {
+	ID        int       `json:"id"`
+	SurveyID  int64     `json:"survey_id"`   // 作为模板的问卷ID
+	UserID    int       `json:"user_id"`     // 创建者id，生成的问卷归属于该管理员
+	Frequency int       `json:"frequency"`   // 周期 1:每周 2:每月
+	Weekday   int       `json:"weekday"`     // 每周的星期几 0:周日 1-6:周一至周六
+	MonthDay  int       `json:"month_day"`   // 每月的几号 1-28
+	Hour      int       `json:"hour"`        // 每期开始的小时
+	Minute    int       `json:"minute"`      // 每期开始的分钟
+	Duration  int       `json:"duration"`    // 每期开放时长，单位：小时
+	Enabled   bool      `json:"enabled"`     // 是否启用
+	NextRunAt time.Time `json:"next_run_at"` // 下一期的开始时间
+}
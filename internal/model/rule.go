@@ -0,0 +1,14 @@
+package model
+
+// Rule 题目显示逻辑模型
+type Rule struct {
+	ID            int    `json:"id"`
+	SurveyID      int64  `json:"survey_id"`      // 问卷ID
+	SerialNum     int    `json:"serial_num"`     // 条件题目序号
+	MatchType     int    `json:"match_type"`     // 条件类型 1:选中选项 2:文本匹配
+	OptionSerial  int    `json:"option_serial"`  // 条件选项序号
+	Text          string `json:"text"`           // 条件匹配文本
+	Action        int    `json:"action"`         // 动作 1:显示目标题 2:隐藏目标题 3:跳转到目标题
+	TargetSerial  int    `json:"target_serial"`  // 目标题目序号
+	TargetSection int    `json:"target_section"` // 目标分页序号 不为0时作用于整个分页
+}
@@ -0,0 +1,11 @@
+package model
+
+// Section 问卷分页模型
+type Section struct {
+	ID               int    `json:"id"`
+	SurveyID         int64  `json:"survey_id"`         // 问卷ID
+	SerialNum        int    `json:"serial_num"`        // 分页序号
+	Title            string `json:"title"`             // 分页标题
+	Desc             string `json:"desc"`              // 分页描述
+	ShuffleQuestions bool   `json:"shuffle_questions"` // 是否打乱分页内题目顺序
+}
@@ -4,21 +4,28 @@ import "time"
 
 // Survey 问卷模型
 type Survey struct {
-	ID            int64     `json:"id" gorm:"primaryKey"` // 问卷id
-	UserID        int       `json:"user_id"`              // 用户id
-	Title         string    `json:"title"`                // 问卷标题
-	Desc          string    `json:"desc"`                 // 问卷描述
-	StartTime     time.Time `json:"start_time"`           // 开始时间
-	Deadline      time.Time `json:"deadline"`             // 截止时间
-	CreatedAt     time.Time `json:"created_at"`           // 创建时间
-	Status        int       `json:"status"`               // 问卷状态  1:未发布 2:已发布 3:已截止
-	DailyLimit    uint      `json:"day_limit"`            // 问卷每日填写限制
-	SumLimit      uint      `json:"sum_limit"`            // 问卷总填写次数限制
-	Verify        bool      `json:"verify"`               // 问卷是否需要统一验证
-	UndergradOnly bool      `json:"undergrad_only"`       // 问卷是否仅限本科生作答
-	Type          uint      `json:"type"`                 // 问卷类型 0:调研 1:投票
-	Num           int       `json:"num"`                  // 问卷填写数量
-	NeedNotify    bool      `json:"need_notify"`          // 是否需要通知
+	ID               int64     `json:"id" gorm:"primaryKey"` // 问卷id
+	UserID           int       `json:"user_id"`              // 用户id
+	Title            string    `json:"title"`                // 问卷标题
+	Desc             string    `json:"desc"`                 // 问卷描述
+	StartTime        time.Time `json:"start_time"`           // 开始时间
+	Deadline         time.Time `json:"deadline"`             // 截止时间
+	CreatedAt        time.Time `json:"created_at"`           // 创建时间
+	Status           int       `json:"status"`               // 问卷状态  1:未发布 2:已发布 3:已截止
+	DailyLimit       uint      `json:"day_limit"`            // 问卷每日填写限制
+	SumLimit         uint      `json:"sum_limit"`            // 问卷总填写次数限制
+	Verify           bool      `json:"verify"`               // 问卷是否需要统一验证
+	UndergradOnly    bool      `json:"undergrad_only"`       // 问卷是否仅限本科生作答
+	Type             uint      `json:"type"`                 // 问卷类型 0:调研 1:投票 2:测验
+	Num              int       `json:"num"`                  // 问卷填写数量
+	NeedNotify       bool      `json:"need_notify"`          // 是否需要通知
+	ShuffleQuestions bool      `json:"shuffle_questions"`    // 是否打乱题目顺序
+	ShowScore        bool      `json:"show_score"`           // 测验提交后是否返回得分
+	Version          int       `json:"version"`              // 问卷版本 已有答卷的问卷修改后递增
+	AutoPublish      bool      `json:"auto_publish"`         // 是否在开始时间自动发布
+	SeriesID         int       `json:"series_id"`            // 所属周期问卷规则ID 0为非周期生成的问卷
+	HideLiveResults  bool      `json:"hide_live_results"`    // 投票截止前是否隐藏实时结果
+	ResultVisibility uint      `json:"result_visibility"`    // 投票结果公开范围
 }
 
 // SurveyResp 问卷响应模型
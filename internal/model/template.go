@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// Template 问卷模板模型
+type Template struct {
+	ID        int       `json:"id"`
+	UserID    int       `json:"user_id"`                // 创建者id
+	Title     string    `json:"title"`                  // 模板标题
+	IsShared  bool      `json:"is_shared"`              // 是否为所有管理员可见的组织模板
+	Content   string    `json:"-" gorm:"type:longtext"` // 问卷定义快照(JSON)
+	CreatedAt time.Time `json:"created_at"`             // 创建时间
+}
@@ -0,0 +1,13 @@
+package model
+
+// Translation 问卷翻译模型，按题目标识和选项序号关联，问卷修改后翻译仍然有效
+type Translation struct {
+	ID           int    `json:"id"`
+	SurveyID     int64  `json:"survey_id"`     // 问卷ID
+	Locale       string `json:"locale"`        // 语言，如 en
+	QuestionKey  string `json:"question_key"`  // 题目标识 为空时为问卷标题和描述的翻译
+	OptionSerial int    `json:"option_serial"` // 选项序号 为0时为题目的翻译
+	IsColumn     bool   `json:"is_column"`     // 是否为矩阵题的列
+	Content      string `json:"content"`       // 标题、题目或选项内容的翻译
+	Description  string `json:"description"`   // 描述的翻译
+}
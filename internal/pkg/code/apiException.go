@@ -54,14 +54,56 @@ var (
 	VoteSumLimitError            = NewError(200531, log.LevelInfo, "总投票次数已达上限")
 	NotUnderGraduateError        = NewError(200532, log.LevelInfo, "当前问卷仅允许本科生提交")
 	WrongOauthUsernameOrPassword = NewError(200534, log.LevelInfo, "统一登录账号或密码错误")
+	LogicError                   = NewError(200535, log.LevelInfo, "题目显示逻辑设置不符合规范")
+	QuestionHiddenError          = NewError(200536, log.LevelInfo, "存在被隐藏题目的作答，请重新检查！")
+	MatrixAnswerError            = NewError(200537, log.LevelInfo, "矩阵题答案不符合要求")
+	ScaleAnswerError             = NewError(200538, log.LevelInfo, "量表题答案超出范围")
+	RankingAnswerError           = NewError(200539, log.LevelInfo, "排序题答案不完整")
+	AnswerFormatError            = NewError(200540, log.LevelInfo, "答案格式不符合要求")
+	AnswerDateError              = NewError(200541, log.LevelInfo, "日期格式不正确")
+	AnswerNumberError            = NewError(200542, log.LevelInfo, "数字格式不正确或超出范围")
+	AnswerEmailError             = NewError(200543, log.LevelInfo, "邮箱格式不正确")
+	AnswerPhoneError             = NewError(200544, log.LevelInfo, "手机号格式不正确")
+	AnswerStudentIDError         = NewError(200545, log.LevelInfo, "学号格式不正确")
+	SectionError                 = NewError(200546, log.LevelInfo, "问卷分页设置有误")
+	OptionFullError              = NewError(200547, log.LevelInfo, "选项名额已满")
+	QuotaError                   = NewError(200548, log.LevelInfo, "问卷配额设置有误")
+	QuotaFullError               = NewError(200549, log.LevelInfo, "当前群体的答卷配额已满")
+	QuizError                    = NewError(200550, log.LevelInfo, "测验分值或答案设置有误")
+	AnswerEditError              = NewError(200551, log.LevelInfo, "当前问卷不支持修改答卷")
+	TemplateNotExist             = NewError(200552, log.LevelInfo, "问卷模板不存在")
+	SurveyVersionError           = NewError(200553, log.LevelInfo, "问卷已更新，请刷新后重新填写")
+	RecurrenceError              = NewError(200554, log.LevelInfo, "周期问卷设置有误")
+	TranslationError             = NewError(200555, log.LevelInfo, "问卷翻译有误")
+	CrossTabError                = NewError(200556, log.LevelInfo, "交叉分析设置有误")
+	LiveResultHiddenError        = NewError(200557, log.LevelInfo, "投票结果将在截止后公布")
+	ResultHiddenError            = NewError(200558, log.LevelInfo, "投票结果暂不公开")
+	OptionRepeatError            = NewError(200559, log.LevelInfo, "选项重复选择")
 	NotFound                     = NewError(200404, log.LevelInfo, http.StatusText(http.StatusNotFound))
 )
 
+// QuestionError 表示与具体题目相关的错误，返回时会附带题目信息以便前端定位
+type QuestionError struct {
+	Err        *Error
+	QuestionID int
+	SerialNum  int
+}
+
 // Error 方法实现了 error 接口，返回错误的消息内容
 func (e *Error) Error() string {
 	return e.Msg
 }
 
+// Error 方法实现了 error 接口，返回错误的消息内容
+func (e *QuestionError) Error() string {
+	return e.Err.Msg
+}
+
+// Unwrap 返回题目错误对应的自定义错误
+func (e *QuestionError) Unwrap() error {
+	return e.Err
+}
+
 // NewError 创建并返回一个新的自定义错误实例
 func NewError(code int, level log.Level, msg string) *Error {
 	return &Error{
@@ -77,6 +119,16 @@ func AbortWithException(c *gin.Context, apiError *Error, err error) {
 	_ = c.AbortWithError(200, apiError) //nolint:errcheck
 }
 
+// AbortWithQuestionException 用于返回与具体题目相关的自定义错误信息
+func AbortWithQuestionException(c *gin.Context, apiError *Error, questionID int, serialNum int, err error) {
+	logError(c, apiError, err)
+	_ = c.AbortWithError(200, &QuestionError{ //nolint:errcheck
+		Err:        apiError,
+		QuestionID: questionID,
+		SerialNum:  serialNum,
+	})
+}
+
 // logError 记录错误日志
 func logError(c *gin.Context, apiErr *Error, err error) {
 	// 构建日志字段
@@ -7,6 +7,7 @@ import (
 
 	"QA-System/internal/global/config"
 
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/mongo/readpref"
@@ -53,8 +54,20 @@ func Init() *mongo.Database {
 	}
 
 	mdb := client.Database(db)
+	createIndexes(mdb)
 
 	// 日志记录
 	zap.L().Info("Connected to MongoDB")
 	return mdb
 }
+
+// createIndexes 创建答卷集合的索引，索引已存在时不会重复创建
+func createIndexes(mdb *mongo.Database) {
+	indexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "surveyid", Value: 1}}},
+		{Keys: bson.D{{Key: "answers.questionid", Value: 1}}},
+	}
+	if _, err := mdb.Collection(QA).Indexes().CreateMany(context.TODO(), indexes); err != nil {
+		zap.L().Error("Failed to create MongoDB indexes:" + err.Error())
+	}
+}
@@ -14,5 +14,11 @@ func autoMigrate(db *gorm.DB) error {
 		&model.Option{},
 		&model.Manage{},
 		&model.Pre{},
+		&model.Rule{},
+		&model.Section{},
+		&model.Quota{},
+		&model.Template{},
+		&model.Recurrence{},
+		&model.Translation{},
 	)
 }
@@ -21,9 +21,14 @@ func Init(r *gin.Engine) {
 			user.POST("/submit", u.SubmitSurvey)
 			user.GET("/get", u.GetSurvey)
 			user.GET("/statistic", u.GetSurveyStatistics)
+			user.GET("/statistic/live", u.GetLiveStatistics)
 			user.POST("/upload/img", u.UploadImg)
 			user.POST("/upload/file", u.UploadFile)
 			user.POST("/oauth", u.Oauth)
+			user.POST("/draft", u.SaveDraft)
+			user.GET("/draft", u.GetDraft)
+			user.GET("/answer", u.GetMyAnswer)
+			user.PUT("/answer", u.UpdateMyAnswer)
 		}
 		admin := api.Group("/admin", middleware.CheckLogin)
 		{
@@ -37,7 +42,11 @@ func Init(r *gin.Engine) {
 			admin.PUT("/update/questions", a.UpdateSurvey)
 			admin.GET("/list/answers", a.GetSurveyAnswers)
 			admin.GET("/statics/answers", a.GetSurveyStatistics)
+			admin.GET("/statics/timeline", a.GetSubmissionTimeline)
 			admin.DELETE("/delete", a.DeleteSurvey)
+			admin.POST("/clone", a.CloneSurvey)
+			admin.GET("/export", a.ExportSurvey)
+			admin.POST("/import", a.ImportSurvey)
 			admin.DELETE("/delete/answersheet", a.DeleteAnswerSheet)
 
 			admin.POST("/permission/create", a.CreatePermission)
@@ -47,6 +56,22 @@ func Init(r *gin.Engine) {
 			admin.GET("/single/question", a.GetSurvey)
 			admin.GET("/download", a.DownloadFile)
 			admin.GET("/download/chooseStatics", a.DownloadChooseFile)
+			admin.GET("/crosstab", a.GetCrossTab)
+			admin.GET("/download/crosstab", a.DownloadCrossTabFile)
+			admin.GET("/quota", a.GetQuotaProgress)
+
+			admin.POST("/template/create", a.CreateTemplate)
+			admin.GET("/template/list", a.GetTemplates)
+			admin.POST("/template/use", a.CreateSurveyByTemplate)
+			admin.DELETE("/template/delete", a.DeleteTemplate)
+
+			admin.POST("/recurrence", a.SetRecurrence)
+			admin.DELETE("/recurrence", a.DeleteRecurrence)
+			admin.GET("/series", a.GetSeries)
+
+			admin.POST("/translation", a.SaveTranslation)
+			admin.GET("/translation", a.GetTranslation)
+			admin.DELETE("/translation", a.DeleteTranslation)
 		}
 	}
 }
@@ -3,7 +3,6 @@ package service
 import (
 	"errors"
 	"fmt"
-	"log"
 	"os"
 	"path/filepath"
 	"sort"
@@ -94,48 +93,96 @@ func CheckPermission(id int, surveyID int64) error {
 	return err
 }
 
-// CreateSurvey 创建问卷
-func CreateSurvey(id int, question_list []dao.QuestionList, status int, surveyType, limit uint,
-	sumLimit uint, verify, undergradOnly bool, ddl, startTime time.Time, title string, desc string,
-	neednot bool) error {
-	var survey model.Survey
-	survey.ID = idgen.NextId()
-	survey.UserID = id
-	survey.Status = status
-	survey.Deadline = ddl
-	survey.Type = surveyType
-	survey.DailyLimit = limit
-	survey.SumLimit = sumLimit
-	survey.Verify = verify
-	survey.UndergradOnly = undergradOnly
-	survey.StartTime = startTime
-	survey.Title = title
-	survey.Desc = desc
-	survey.NeedNotify = neednot
-	survey, err := d.CreateSurvey(ctx, survey)
+// CreateSurvey 创建问卷，返回新问卷的ID
+func CreateSurvey(uid int, status int, surveyType uint, base dao.BaseConfig, config dao.QuestionConfig) (int64, error) {
+	return createSurvey(d, uid, status, surveyType, base, config)
+}
+
+// createSurvey 使用给定的数据访问对象创建问卷，以便与其他写入放在同一事务中
+func createSurvey(db dao.Daos, uid int, status int, surveyType uint, base dao.BaseConfig,
+	config dao.QuestionConfig) (int64, error) {
+	survey := model.Survey{ID: idgen.NextId(), UserID: uid, Status: status}
+	if err := applySurveyConfig(&survey, surveyType, base, config); err != nil {
+		return 0, err
+	}
+	survey, err := db.CreateSurvey(ctx, survey)
+	if err != nil {
+		return 0, err
+	}
+	_, err = createQuestionsAndOptions(db, config.QuestionList, survey.ID, survey.Version)
+	if err != nil {
+		return 0, err
+	}
+	err = createRules(db, config.Logic, survey.ID)
+	if err != nil {
+		return 0, err
+	}
+	err = createSections(db, config.Sections, survey.ID)
+	if err != nil {
+		return 0, err
+	}
+	err = createQuotas(db, base.Quotas, survey.ID)
+	return survey.ID, err
+}
+
+// applySurveyConfig 将问卷类型、基本配置以及标题和描述写入问卷，时间按 RFC3339 解析
+func applySurveyConfig(survey *model.Survey, surveyType uint, base dao.BaseConfig, config dao.QuestionConfig) error {
+	deadline, err := time.Parse(time.RFC3339, base.EndTime)
 	if err != nil {
 		return err
 	}
-	_, err = createQuestionsAndOptions(question_list, survey.ID)
-	return err
+	startTime, err := time.Parse(time.RFC3339, base.StartTime)
+	if err != nil {
+		return err
+	}
+	survey.Title = config.Title
+	survey.Desc = config.Desc
+	survey.Type = surveyType
+	survey.StartTime = startTime
+	survey.Deadline = deadline
+	survey.DailyLimit = base.DailyLimit
+	survey.SumLimit = base.SumLimit
+	survey.Verify = base.Verify
+	survey.UndergradOnly = base.UndergradOnly
+	survey.NeedNotify = base.NeedNotify
+	survey.ShuffleQuestions = base.ShuffleQuestions
+	survey.ShowScore = base.ShowScore
+	survey.AutoPublish = base.AutoPublish
+	survey.HideLiveResults = base.HideLiveResults
+	survey.ResultVisibility = base.ResultVisibility
+	return nil
 }
 
-// UpdateSurveyStatus 更新问卷状态
+// UpdateSurveyStatus 更新问卷状态，并向插件分发问卷状态事件
 func UpdateSurveyStatus(id int64, status int) error {
 	err := d.UpdateSurveyStatus(ctx, id, status)
-	return err
+	if err != nil {
+		return err
+	}
+	dispatchSurveyEvent(id, status)
+	return nil
 }
 
 // UpdateSurvey 更新问卷
-func UpdateSurvey(id int64, question_list []dao.QuestionList, surveyType,
-	limit uint, sumLimit uint, verify, undergradOnly bool, desc string, title string, ddl, startTime time.Time,
-	needNotify bool) error {
+// 问卷已发布或已有答卷时保留原有问题作为历史版本，新的问题归入新版本，答卷通过题目标识对应到各版本
+func UpdateSurvey(id int64, surveyType uint, base dao.BaseConfig, config dao.QuestionConfig) error {
+	survey, err := d.GetSurveyByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	info := model.Survey{ID: id}
+	if err := applySurveyConfig(&info, surveyType, base, config); err != nil {
+		return err
+	}
+	if survey.Status == 2 || survey.Num != 0 {
+		return updateSurveyVersion(survey, &info, base, config)
+	}
 	// 遍历原有问题，删除对应选项
 	var oldQuestions []model.Question
 	var oldImgs []string
 	newImgs := make([]string, 0)
 	// 获取原有图片
-	oldQuestions, err := d.GetQuestionsBySurveyID(ctx, id)
+	oldQuestions, err = d.GetQuestionsBySurveyID(ctx, id)
 	if err != nil {
 		return err
 	}
@@ -169,17 +216,20 @@ func UpdateSurvey(id int64, question_list []dao.QuestionList, surveyType,
 		}
 	}
 	// 修改问卷信息
-	err = d.UpdateSurvey(ctx, id, surveyType, limit, sumLimit, verify, undergradOnly, desc, title, ddl, startTime,
-		needNotify)
+	err = d.UpdateSurvey(ctx, &info)
 	if err != nil {
 		return err
 	}
 	// 重新添加问题和选项
-	imgs, err := createQuestionsAndOptions(question_list, id)
+	imgs, err := createQuestionsAndOptions(d, config.QuestionList, id, survey.Version)
 	if err != nil {
 		return err
 	}
 	newImgs = append(newImgs, imgs...)
+	err = recreateSurveyConfig(d, id, config.Logic, config.Sections, base.Quotas)
+	if err != nil {
+		return err
+	}
 	// 删除无用图片
 	for _, oldImg := range oldImgs {
 		if !contains(newImgs, oldImg) {
@@ -192,6 +242,74 @@ func UpdateSurvey(id int64, question_list []dao.QuestionList, surveyType,
 	return nil
 }
 
+// updateSurveyVersion 创建问卷的新版本，原有问题和图片保留给历史答卷使用
+// 提交的题目标识与当前版本的题目均不对应时返回 ErrQuestionKeyMismatch
+func updateSurveyVersion(survey *model.Survey, info *model.Survey, base dao.BaseConfig,
+	config dao.QuestionConfig) error {
+	current, err := d.GetQuestionsBySurveyID(ctx, survey.ID)
+	if err != nil {
+		return err
+	}
+	if err := checkVersionKeys(current, config.QuestionList); err != nil {
+		return err
+	}
+	version := survey.Version + 1
+	// 问卷信息、新版本题目和问卷配置在同一事务中修改，失败时不留下不完整的新版本
+	err = d.Transaction(ctx, func(tx dao.Daos) error {
+		err := tx.UpdateSurvey(ctx, info)
+		if err != nil {
+			return err
+		}
+		_, err = createQuestionsAndOptions(tx, config.QuestionList, survey.ID, version)
+		if err != nil {
+			return err
+		}
+		err = tx.UpdateSurveyVersion(ctx, survey.ID, version)
+		if err != nil {
+			return err
+		}
+		return recreateSurveyConfig(tx, survey.ID, config.Logic, config.Sections, base.Quotas)
+	})
+	if err != nil {
+		return err
+	}
+	err = dao.DeleteAllQuestionCache(ctx)
+	if err != nil {
+		return err
+	}
+	// 草稿中的题目属于旧版本，无法继续提交
+	return deleteDrafts(survey.ID)
+}
+
+// recreateSurveyConfig 重新添加问卷的显示逻辑、分页和配额
+func recreateSurveyConfig(db dao.Daos, id int64, logic []dao.Rule, sections []dao.Section,
+	quotas []dao.Quota) error {
+	// 重新添加显示逻辑
+	err := db.DeleteRulesBySurveyID(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = createRules(db, logic, id)
+	if err != nil {
+		return err
+	}
+	// 重新添加分页
+	err = db.DeleteSectionsBySurveyID(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = createSections(db, sections, id)
+	if err != nil {
+		return err
+	}
+	// 重新添加配额
+	err = db.DeleteQuotasBySurveyID(ctx, id)
+	if err != nil {
+		return err
+	}
+	return createQuotas(db, quotas, id)
+}
+
 // UserInManage 用户是否在管理中
 func UserInManage(uid int, sid int64) bool {
 	_, err := d.GetManageByUIDAndSID(ctx, uid, sid)
@@ -201,7 +319,7 @@ func UserInManage(uid int, sid int64) bool {
 // DeleteSurvey 删除问卷
 func DeleteSurvey(id int64) error {
 	var questions []model.Question
-	questions, err := d.GetQuestionsBySurveyID(ctx, id)
+	questions, err := d.GetAllQuestionsBySurveyID(ctx, id)
 	if err != nil {
 		return err
 	}
@@ -249,6 +367,42 @@ func DeleteSurvey(id int64) error {
 	if err != nil {
 		return err
 	}
+	err = d.DeleteRulesBySurveyID(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = d.DeleteSectionsBySurveyID(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = deleteOptionCapacity(id)
+	if err != nil {
+		return err
+	}
+	err = d.DeleteQuotasBySurveyID(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = d.DeleteRecurrenceBySurveyID(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = d.DeleteTranslationsBySurveyID(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = deleteQuotaCounters(id)
+	if err != nil {
+		return err
+	}
+	err = deleteDrafts(id)
+	if err != nil {
+		return err
+	}
+	err = deleteLiveSequence(id)
+	if err != nil {
+		return err
+	}
 	err = dao.DeleteAllQuestionCache(ctx)
 	if err != nil {
 		return err
@@ -268,45 +422,43 @@ func DeleteSurvey(id int64) error {
 // GetSurveyAnswers 获取问卷答案
 func GetSurveyAnswers(id int64, num int, size int, text string, unique bool) (dao.AnswersResonse, *int64, error) {
 	var answerSheets []dao.AnswerSheet
-	data := make([]dao.QuestionAnswers, 0)
 	times := make([]string, 0)
 	aids := make([]primitive.ObjectID, 0)
+	scores := make([]float64, 0)
 	var total *int64
+	survey, err := d.GetSurveyByID(ctx, id)
+	if err != nil {
+		return dao.AnswersResonse{}, nil, err
+	}
 	// 获取问题
 	questions, err := d.GetQuestionsBySurveyID(ctx, id)
 	if err != nil {
 		return dao.AnswersResonse{}, nil, err
 	}
-	// 初始化data
-	for _, question := range questions {
-		var q dao.QuestionAnswers
-		q.Title = question.Subject
-		q.QuestionType = question.QuestionType
-		q.Answers = make([]string, 0)
-		data = append(data, q)
-	}
 	// 获取答卷
 	answerSheets, total, err = d.GetAnswerSheetBySurveyID(ctx, id, num, size, text, unique)
 	if err != nil {
 		return dao.AnswersResonse{}, nil, err
 	}
-	// 填充data
+	// 历史版本的答卷按题目标识对应到当前版本
+	answerSheets, err = mapAnswerSheets(questions, answerSheets)
+	if err != nil {
+		return dao.AnswersResonse{}, nil, err
+	}
+	data, err := buildQuestionAnswers(questions, answerSheets)
+	if err != nil {
+		return dao.AnswersResonse{}, nil, err
+	}
 	for _, answerSheet := range answerSheets {
 		times = append(times, answerSheet.Time)
 		aids = append(aids, answerSheet.AnswerID)
-		for _, answer := range answerSheet.Answers {
-			question, err := d.GetQuestionByID(ctx, answer.QuestionID)
-			if err != nil {
-				return dao.AnswersResonse{}, nil, err
-			}
-			for i, q := range data {
-				if q.Title == question.Subject {
-					data[i].Answers = append(data[i].Answers, answer.Content)
-				}
-			}
-		}
+		scores = append(scores, answerSheet.Score)
 	}
-	return dao.AnswersResonse{QuestionAnswers: data, AnswerIDs: aids, Time: times}, total, nil
+	response := dao.AnswersResonse{QuestionAnswers: data, AnswerIDs: aids, Time: times}
+	if survey.Type == 2 {
+		response.Scores = scores
+	}
+	return response, total, nil
 }
 
 // GetSurveyByUserID 获取用户的所有问卷
@@ -404,45 +556,49 @@ func GetManagedSurveyByUserID(userId int) ([]model.Manage, error) {
 
 // GetAllSurveyAnswers 获取所有问卷答案
 func GetAllSurveyAnswers(id int64) (dao.AnswersResonse, error) {
-	data := make([]dao.QuestionAnswers, 0)
 	answerSheets := make([]dao.AnswerSheet, 0)
 	questions := make([]model.Question, 0)
 	times := make([]string, 0)
-	questions, err := d.GetQuestionsBySurveyID(ctx, id)
+	scores := make([]float64, 0)
+	survey, err := d.GetSurveyByID(ctx, id)
 	if err != nil {
 		return dao.AnswersResonse{}, err
 	}
-	for _, question := range questions {
-		var q dao.QuestionAnswers
-		q.Title = question.Subject
-		q.QuestionType = question.QuestionType
-		data = append(data, q)
+	questions, err = d.GetQuestionsBySurveyID(ctx, id)
+	if err != nil {
+		return dao.AnswersResonse{}, err
 	}
 	answerSheets, _, err = d.GetAnswerSheetBySurveyID(ctx, id, 0, 0, "", true)
 	if err != nil {
 		return dao.AnswersResonse{}, err
 	}
+	// 历史版本的答卷按题目标识对应到当前版本
+	answerSheets, err = mapAnswerSheets(questions, answerSheets)
+	if err != nil {
+		return dao.AnswersResonse{}, err
+	}
+	data, err := buildQuestionAnswers(questions, answerSheets)
+	if err != nil {
+		return dao.AnswersResonse{}, err
+	}
 	for _, answerSheet := range answerSheets {
 		times = append(times, answerSheet.Time)
-		for _, answer := range answerSheet.Answers {
-			question, err := d.GetQuestionByID(ctx, answer.QuestionID)
-			if err != nil {
-				return dao.AnswersResonse{}, err
-			}
-			for i, q := range data {
-				if q.Title == question.Subject {
-					data[i].Answers = append(data[i].Answers, answer.Content)
-				}
-			}
-		}
+		scores = append(scores, answerSheet.Score)
 	}
-	return dao.AnswersResonse{QuestionAnswers: data, Time: times}, nil
+	response := dao.AnswersResonse{QuestionAnswers: data, Time: times}
+	if survey.Type == 2 {
+		response.Scores = scores
+	}
+	return response, nil
 }
 
-// GetSurveyAnswersBySurveyID 根据问卷编号获取问卷答案
+// GetSurveyAnswersBySurveyID 根据问卷编号获取问卷答案，历史版本的答案对应到当前版本的题目
 func GetSurveyAnswersBySurveyID(sid int64) ([]dao.AnswerSheet, error) {
 	answerSheets, _, err := d.GetAnswerSheetBySurveyID(ctx, sid, 0, 0, "", true)
-	return answerSheets, err
+	if err != nil {
+		return nil, err
+	}
+	return MapAnswerSheets(sid, answerSheets)
 }
 
 func contains(arr []string, str string) bool {
@@ -473,7 +629,8 @@ func getOldImgs(questions []model.Question) ([]string, error) {
 func getDelImgs(questions []model.Question, answerSheets []dao.AnswerSheet) ([]string, error) {
 	imgs := make([]string, 0)
 	for _, question := range questions {
-		if question.Img != "" {
+		// 各版本的题目可能共用同一图片
+		if question.Img != "" && !contains(imgs, question.Img) {
 			imgs = append(imgs, question.Img)
 		}
 		var options []model.Option
@@ -482,7 +639,7 @@ func getDelImgs(questions []model.Question, answerSheets []dao.AnswerSheet) ([]s
 			return nil, err
 		}
 		for _, option := range options {
-			if option.Img != "" {
+			if option.Img != "" && !contains(imgs, option.Img) {
 				imgs = append(imgs, option.Img)
 			}
 		}
@@ -517,11 +674,18 @@ func getDelFiles(answerSheets []dao.AnswerSheet) ([]string, error) {
 	return files, nil
 }
 
-func createQuestionsAndOptions(question_list []dao.QuestionList, sid int64) ([]string, error) {
+func createQuestionsAndOptions(db dao.Daos, question_list []dao.QuestionList, sid int64,
+	version int) ([]string, error) {
 	imgs := make([]string, 0)
 	for _, question_list := range question_list {
 		var q model.Question
+		q.Version = version
+		q.Key = question_list.Key
+		if q.Key == "" {
+			q.Key = newQuestionKey()
+		}
 		q.SerialNum = question_list.SerialNum
+		q.SectionNum = question_list.SectionNum
 		q.SurveyID = sid
 		q.Subject = question_list.Subject
 		q.Description = question_list.Description
@@ -533,8 +697,20 @@ func createQuestionsAndOptions(question_list []dao.QuestionList, sid int64) ([]s
 		q.MaximumOption = question_list.QuestionSetting.MaximumOption
 		q.MinimumOption = question_list.QuestionSetting.MinimumOption
 		q.Reg = question_list.QuestionSetting.Reg
+		q.ShuffleOptions = question_list.QuestionSetting.ShuffleOptions
+		q.Score = question_list.QuestionSetting.Score
+		q.AnswerKey = question_list.QuestionSetting.AnswerKey
+		q.InputType = question_list.QuestionSetting.InputType
+		q.ScaleType = question_list.QuestionSetting.ScaleType
+		q.MinValue = question_list.QuestionSetting.MinValue
+		q.MaxValue = question_list.QuestionSetting.MaxValue
+		q.Step = question_list.QuestionSetting.Step
+		// NPS 固定为 0-10 分
+		if q.QuestionType == 8 && q.ScaleType == 2 {
+			q.MinValue, q.MaxValue, q.Step = 0, 10, 1
+		}
 		imgs = append(imgs, question_list.Img)
-		q, err := d.CreateQuestion(ctx, q)
+		q, err := db.CreateQuestion(ctx, q)
 		if err != nil {
 			return nil, err
 		}
@@ -545,8 +721,26 @@ func createQuestionsAndOptions(question_list []dao.QuestionList, sid int64) ([]s
 			o.SerialNum = option.SerialNum
 			o.Img = option.Img
 			o.Description = option.Description
+			o.Capacity = option.Capacity
+			o.IsCorrect = option.IsCorrect
+			o.Score = option.Score
 			imgs = append(imgs, option.Img)
-			err := d.CreateOption(ctx, o)
+			err := db.CreateOption(ctx, o)
+			if err != nil {
+				return nil, err
+			}
+		}
+		// 矩阵题的列
+		for _, column := range question_list.Columns {
+			var o model.Option
+			o.Content = column.Content
+			o.QuestionID = q.ID
+			o.SerialNum = column.SerialNum
+			o.Img = column.Img
+			o.Description = column.Description
+			o.IsColumn = true
+			imgs = append(imgs, column.Img)
+			err := db.CreateOption(ctx, o)
 			if err != nil {
 				return nil, err
 			}
@@ -570,9 +764,15 @@ func aesEncryptPassword(user *model.User) {
 }
 
 // HandleDownloadFile 处理下载文件
-func HandleDownloadFile(answers dao.AnswersResonse, survey *model.Survey) (string, error) {
+func HandleDownloadFile(answers dao.AnswersResonse, survey *model.Survey,
+	scoreStats *GetScoreStatistics) (string, error) {
 	questionAnswers := answers.QuestionAnswers
 	times := answers.Time
+	// 测验在提交时间后增加得分列
+	offset := 2
+	if survey.Type == 2 {
+		offset = 3
+	}
 	// 创建一个新的Excel文件
 	f := excelize.NewFile()
 	streamWriter, err := f.NewStreamWriter("Sheet1")
@@ -590,11 +790,14 @@ func HandleDownloadFile(answers dao.AnswersResonse, survey *model.Survey) (strin
 	maxWidths := make(map[int]int)
 	maxWidths[0] = 7
 	maxWidths[1] = 20
+	if survey.Type == 2 {
+		maxWidths[2] = 7
+	}
 	for i, qa := range questionAnswers {
-		maxWidths[i+2] = len(qa.Title)
+		maxWidths[i+offset] = len(qa.Title)
 		for _, answer := range qa.Answers {
-			if len(answer) > maxWidths[i+2] {
-				maxWidths[i+2] = len(answer)
+			if len(answer) > maxWidths[i+offset] {
+				maxWidths[i+offset] = len(answer)
 			}
 		}
 	}
@@ -611,6 +814,9 @@ func HandleDownloadFile(answers dao.AnswersResonse, survey *model.Survey) (strin
 	rowData := make([]any, 0)
 	rowData = append(rowData, excelize.Cell{Value: "序号", StyleID: styleID},
 		excelize.Cell{Value: "提交时间", StyleID: styleID})
+	if survey.Type == 2 {
+		rowData = append(rowData, excelize.Cell{Value: "得分", StyleID: styleID})
+	}
 	for _, qa := range questionAnswers {
 		rowData = append(rowData, excelize.Cell{Value: qa.Title, StyleID: styleID})
 	}
@@ -620,13 +826,16 @@ func HandleDownloadFile(answers dao.AnswersResonse, survey *model.Survey) (strin
 	// 写入数据
 	for i, t := range times {
 		row := []any{i + 1, t}
+		if survey.Type == 2 && i < len(answers.Scores) {
+			row = append(row, answers.Scores[i])
+		}
 		for j, qa := range questionAnswers {
 			if len(qa.Answers) <= i {
 				continue
 			}
 			answer := qa.Answers[i]
 			row = append(row, answer)
-			colName, err := excelize.ColumnNumberToName(j + 3)
+			colName, err := excelize.ColumnNumberToName(j + offset + 1)
 			if err != nil {
 				return "", errors.New("转换列名失败原因: " + err.Error())
 			}
@@ -642,6 +851,12 @@ func HandleDownloadFile(answers dao.AnswersResonse, survey *model.Survey) (strin
 	if err := streamWriter.Flush(); err != nil {
 		return "", errors.New("关闭失败原因: " + err.Error())
 	}
+	// 测验增加得分分布表
+	if scoreStats != nil {
+		if err := writeScoreStatsSheet(f, scoreStats, styleID); err != nil {
+			return "", errors.New("写入得分分布失败原因: " + err.Error())
+		}
+	}
 	// 保存Excel文件
 	fileName := survey.Title + ".xlsx"
 	filePath := "./public/xlsx/" + fileName
@@ -708,147 +923,78 @@ func DeleteOauthRecord(sid int64) error {
 
 // DeleteAnswerSheetByAnswerID 根据问卷ID删除问卷
 func DeleteAnswerSheetByAnswerID(answerID primitive.ObjectID) error {
-	err := d.DeleteAnswerSheetByAnswerID(ctx, answerID)
-	return err
+	answerSheet, err := d.GetAnswerSheetByAnswerID(ctx, answerID)
+	if err != nil {
+		return err
+	}
+	err = d.DeleteAnswerSheetByAnswerID(ctx, answerID)
+	if err != nil {
+		return err
+	}
+	if answerSheet.Unique {
+		go publishLiveResult(answerSheet.SurveyID, answerSheet.Answers, nil, -1)
+	}
+	// 释放答卷占用的选项名额
+	if err := releaseAnswerSheetCapacity(answerSheet); err != nil {
+		return err
+	}
+	// 删除答卷对应的记录并释放配额
+	return releaseRecordQuota(answerSheet.SurveyID, answerID)
 }
 
-// GetAnswerSheetByAnswerID 根据答卷ID删除答卷
-func GetAnswerSheetByAnswerID(answerID primitive.ObjectID) error {
-	err := d.GetAnswerSheetByAnswerID(ctx, answerID)
-	return err
+// writeScoreStatsSheet 写入测验的得分概况和各分数的人数分布
+func writeScoreStatsSheet(f *excelize.File, scoreStats *GetScoreStatistics, styleID int) error {
+	const sheet = "得分分布"
+	if _, err := f.NewSheet(sheet); err != nil {
+		return err
+	}
+	rows := [][]any{
+		{
+			excelize.Cell{Value: "答卷数量", StyleID: styleID}, excelize.Cell{Value: "满分", StyleID: styleID},
+			excelize.Cell{Value: "平均分", StyleID: styleID}, excelize.Cell{Value: "中位数", StyleID: styleID},
+			excelize.Cell{Value: "最高分", StyleID: styleID}, excelize.Cell{Value: "最低分", StyleID: styleID},
+		},
+		{scoreStats.Count, scoreStats.FullScore, scoreStats.Mean, scoreStats.Median, scoreStats.Max, scoreStats.Min},
+		{},
+		{
+			excelize.Cell{Value: "分数", StyleID: styleID}, excelize.Cell{Value: "人数", StyleID: styleID},
+			excelize.Cell{Value: "百分比", StyleID: styleID},
+		},
+	}
+	for _, point := range scoreStats.Distribution {
+		rows = append(rows, []any{point.Content, point.Count, point.Percent})
+	}
+	for i, row := range rows {
+		if err := f.SetSheetRow(sheet, fmt.Sprintf("A%d", i+1), &row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetAnswerSheetByAnswerID 根据答卷ID获取答卷
+func GetAnswerSheetByAnswerID(answerID primitive.ObjectID) (*dao.AnswerSheet, error) {
+	return d.GetAnswerSheetByAnswerID(ctx, answerID)
 }
 
 // GetOptionCount 选项数据
 type GetOptionCount struct {
-	SerialNum int    `json:"serial_num"` // 选项序号
-	Content   string `json:"content"`    // 选项内容
-	Count     int    `json:"count"`      // 选项数量
-	Percent   string `json:"percent"`    // 占比百分比，保留两位小数
+	SerialNum int    `json:"serial_num"`     // 选项序号
+	Content   string `json:"content"`        // 选项内容
+	Count     int    `json:"count"`          // 选项数量
+	Percent   string `json:"percent"`        // 占比百分比，保留两位小数
+	Rank      int    `json:"rank,omitempty"` // 选择题中按数量的排名
 }
 
 // GetChooseStatisticsResponse 问题模型
 type GetChooseStatisticsResponse struct {
-	SerialNum    int              `json:"serial_num"`    // 问题序号
-	Question     string           `json:"question"`      // 问题内容
-	QuestionType int              `json:"question_type"` // 问题类型  1:单选 2:多选
-	Options      []GetOptionCount `json:"options"`       // 选项内容
-}
-
-// GenerateQuestionStats 生成问卷题目统计结果
-func GenerateQuestionStats(questions []model.Question, answerSheets []dao.AnswerSheet) []GetChooseStatisticsResponse {
-	questionMap := make(map[int]model.Question)
-	optionsMap := make(map[int][]model.Option)
-	optionAnswerMap := make(map[int]map[string]model.Option)
-	optionSerialNumMap := make(map[int]map[int]model.Option)
-	for _, question := range questions {
-		questionMap[question.ID] = question
-		optionAnswerMap[question.ID] = make(map[string]model.Option)
-		optionSerialNumMap[question.ID] = make(map[int]model.Option)
-		options, err := GetOptionsByQuestionID(question.ID)
-		if err != nil {
-			log.Println("Error fetching options for questionID:", question.ID)
-			continue
-		}
-		optionsMap[question.ID] = options
-		for _, option := range options {
-			optionAnswerMap[question.ID][option.Content] = option
-			optionSerialNumMap[question.ID][option.SerialNum] = option
-		}
-	}
-
-	optionCounts := make(map[int]map[int]int)
-	for _, sheet := range answerSheets {
-		for _, answer := range sheet.Answers {
-			options := optionsMap[answer.QuestionID]
-			question := questionMap[answer.QuestionID]
-
-			// 初始化外层 map：如果某题还没记录，先创建一个 map[int]int 作为它的值
-			if _, ok := optionCounts[question.ID]; !ok {
-				optionCounts[question.ID] = make(map[int]int)
-
-				// 初始化所有选项的计数为 0（避免后续统计遗漏）
-				for _, option := range options {
-					optionCounts[question.ID][option.SerialNum] = 0
-				}
-			}
-
-			// 支持单选题、多选题：用 "┋" 分割用户选的答案
-			if question.QuestionType == 1 || question.QuestionType == 2 {
-				answerOptions := strings.Split(answer.Content, "┋")
-				questionOptions := optionAnswerMap[answer.QuestionID]
-
-				for _, answerOption := range answerOptions {
-					if questionOptions != nil {
-						option, exists := questionOptions[answerOption]
-						if exists {
-							// 该选项存在，序号对应的次数 +1
-							optionCounts[answer.QuestionID][option.SerialNum]++
-							continue
-						}
-					}
-					// “其他”选项，统一用 SerialNum = 0 表示
-					optionCounts[answer.QuestionID][0]++
-				}
-			}
-		}
-	}
-	response := make([]GetChooseStatisticsResponse, 0, len(optionCounts))
-	for qid, optionCountMap := range optionCounts {
-		q := questionMap[qid]
-		if q.QuestionType != 1 && q.QuestionType != 2 {
-			continue
-		}
-		total := 0
-		for _, count := range optionCountMap {
-			total += count
-		}
-
-		var qOptions []GetOptionCount
-		if q.OtherOption {
-			qOptions = append(qOptions, GetOptionCount{
-				SerialNum: 0,
-				Content:   "其他",
-				Count:     optionCountMap[0],
-				Percent:   fmt.Sprintf("%.2f%%", float64(optionCountMap[0])*100/float64(total)),
-			})
-		}
-
-		// 排序
-		serialNums := make([]int, 0, len(optionCountMap))
-		for serial := range optionCountMap {
-			if serial != 0 {
-				serialNums = append(serialNums, serial)
-			}
-		}
-		sort.Ints(serialNums)
-
-		for _, serial := range serialNums {
-			count := optionCountMap[serial]
-			op := optionSerialNumMap[qid][serial]
-			percent := "0.00%"
-			if total > 0 {
-				percent = fmt.Sprintf("%.2f%%", float64(count)*100/float64(total))
-			}
-			qOptions = append(qOptions, GetOptionCount{
-				SerialNum: serial,
-				Content:   op.Content,
-				Count:     count,
-				Percent:   percent,
-			})
-		}
-
-		response = append(response, GetChooseStatisticsResponse{
-			SerialNum:    q.SerialNum,
-			Question:     q.Subject,
-			QuestionType: q.QuestionType,
-			Options:      qOptions,
-		})
-	}
-	// 按序号排序
-	sort.Slice(response, func(i, j int) bool {
-		return response[i].SerialNum < response[j].SerialNum
-	})
-	return response
+	SerialNum    int                 `json:"serial_num"`        // 问题序号
+	Question     string              `json:"question"`          // 问题内容
+	QuestionType int                 `json:"question_type"`     // 问题类型  1:单选 2:多选 7:矩阵 8:量表 9:排序
+	Options      []GetOptionCount    `json:"options"`           // 选项内容，量表题为各数值的分布
+	Rows         []GetMatrixRowCount `json:"rows,omitempty"`    // 矩阵题各行统计
+	Scale        *GetScaleStatistics `json:"scale,omitempty"`   // 量表题统计
+	Ranking      []GetRankingCount   `json:"ranking,omitempty"` // 排序题统计
 }
 
 // HandleChooseStatistics 导出投票结果
@@ -864,6 +1010,34 @@ func HandleChooseStatistics(survey *model.Survey, response []GetChooseStatistics
 			row := []any{opt.Content, opt.Count, opt.Percent}
 			rows = append(rows, row)
 		}
+		// 矩阵题每行一条记录，每列为"票数(百分比)"
+		if len(stat.Rows) > 0 {
+			headers = []string{"行内容"}
+			for _, opt := range stat.Rows[0].Options {
+				headers = append(headers, opt.Content)
+			}
+			for _, r := range stat.Rows {
+				row := []any{r.Content}
+				for _, opt := range r.Options {
+					row = append(row, fmt.Sprintf("%d(%s)", opt.Count, opt.Percent))
+				}
+				rows = append(rows, row)
+			}
+		}
+		// 排序题每个选项一条记录
+		if len(stat.Ranking) > 0 {
+			headers = []string{"选项内容", "Borda得分", "平均排名", "排名"}
+			for _, r := range stat.Ranking {
+				rows = append(rows, []any{r.Content, r.Borda, r.AverageRank, r.Rank})
+			}
+		}
+		// 量表题追加汇总数据
+		if stat.Scale != nil {
+			rows = append(rows, []any{"平均值", stat.Scale.Mean, ""}, []any{"中位数", stat.Scale.Median, ""})
+			if stat.Scale.NPS != nil {
+				rows = append(rows, []any{"NPS", stat.Scale.NPS.Score, ""})
+			}
+		}
 
 		sheet := excel.Sheet{
 			Name:    sheetName,
@@ -874,7 +1048,11 @@ func HandleChooseStatistics(survey *model.Survey, response []GetChooseStatistics
 	}
 
 	fileData := excel.File{Sheets: sheets}
-	fileName := survey.Title + ".xlsx"
+	return saveExcelFile(fileData, survey.Title+".xlsx")
+}
+
+// saveExcelFile 将统计数据保存为 Excel 文件，返回文件的下载地址
+func saveExcelFile(fileData excel.File, fileName string) (string, error) {
 	filePath := "./public/xlsx/"
 
 	// 创建目录
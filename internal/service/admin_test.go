@@ -0,0 +1,81 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"QA-System/internal/dao"
+	"QA-System/internal/model"
+)
+
+func TestApplySurveyConfig(t *testing.T) {
+	cst := time.FixedZone("CST", 8*60*60)
+	config := dao.QuestionConfig{Title: "标题", Desc: "描述"}
+	tests := []struct {
+		name    string
+		base    dao.BaseConfig
+		want    model.Survey
+		wantErr bool
+	}{
+		{
+			name: "写入全部配置",
+			base: dao.BaseConfig{
+				StartTime:        "2024-10-01T08:00:00+08:00",
+				EndTime:          "2024-10-08T08:00:00+08:00",
+				DailyLimit:       1,
+				SumLimit:         3,
+				Verify:           true,
+				NeedNotify:       true,
+				ShowScore:        true,
+				HideLiveResults:  true,
+				ResultVisibility: ResultVisibleAfterDeadline,
+			},
+			want: model.Survey{
+				ID:               1,
+				Title:            "标题",
+				Desc:             "描述",
+				Type:             1,
+				StartTime:        time.Date(2024, 10, 1, 8, 0, 0, 0, cst),
+				Deadline:         time.Date(2024, 10, 8, 8, 0, 0, 0, cst),
+				DailyLimit:       1,
+				SumLimit:         3,
+				Verify:           true,
+				NeedNotify:       true,
+				ShowScore:        true,
+				HideLiveResults:  true,
+				ResultVisibility: ResultVisibleAfterDeadline,
+			},
+		},
+		{
+			name:    "开始时间格式错误",
+			base:    dao.BaseConfig{StartTime: "2024-10-01 08:00:00", EndTime: "2024-10-08T08:00:00+08:00"},
+			wantErr: true,
+		},
+		{
+			name:    "截止时间格式错误",
+			base:    dao.BaseConfig{StartTime: "2024-10-01T08:00:00+08:00", EndTime: ""},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			survey := model.Survey{ID: 1}
+			err := applySurveyConfig(&survey, 1, tt.base, config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("applySurveyConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !survey.StartTime.Equal(tt.want.StartTime) || !survey.Deadline.Equal(tt.want.Deadline) {
+				t.Errorf("applySurveyConfig() time = %v ~ %v, want %v ~ %v",
+					survey.StartTime, survey.Deadline, tt.want.StartTime, tt.want.Deadline)
+			}
+			survey.StartTime, survey.Deadline = tt.want.StartTime, tt.want.Deadline
+			if !reflect.DeepEqual(survey, tt.want) {
+				t.Errorf("applySurveyConfig() = %+v, want %+v", survey, tt.want)
+			}
+		})
+	}
+}
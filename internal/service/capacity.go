@@ -0,0 +1,186 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"QA-System/internal/dao"
+	"QA-System/internal/model"
+	"QA-System/internal/pkg/redis"
+
+	redisPkg "github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// ErrOptionFull 选项名额已满
+var ErrOptionFull = errors.New("选项名额已满")
+
+// reserveScript 原子地检查并占用多个名额计数，任一计数已满时不占用任何名额
+// 返回 0 表示占用成功，否则返回已满计数在 KEYS 中的下标(从 1 开始)
+var reserveScript = redisPkg.NewScript(`
+for i, key in ipairs(KEYS) do
+	local used = tonumber(redis.call("GET", key) or "0")
+	if used + 1 > tonumber(ARGV[i]) then
+		return i
+	end
+end
+for _, key in ipairs(KEYS) do
+	redis.call("INCR", key)
+end
+return 0
+`)
+
+// capacityKey 选项名额计数的键，使用题目标识和选项序号以便修改问卷或调整题目顺序后计数仍然有效
+func capacityKey(sid int64, questionKey string, optionSerial int) string {
+	return fmt.Sprintf("capacity:sid:%d:q:%s:o:%d", sid, questionKey, optionSerial)
+}
+
+// ReserveOptionCapacity 为答卷中选择的限额选项占用名额
+// 返回已占用名额的键，选项已满时返回 ErrOptionFull 以及对应的问题
+func ReserveOptionCapacity(sid int64, data []dao.QuestionsList) ([]string, *model.Question, error) {
+	keys := make([]string, 0)
+	capacities := make([]any, 0)
+	questions := make([]*model.Question, 0)
+	for _, q := range data {
+		question, err := d.GetQuestionByID(ctx, q.QuestionID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if (question.QuestionType != 1 && question.QuestionType != 2) || q.Answer == "" {
+			continue
+		}
+		// 同一选项只占用一个名额
+		selected := make(map[string]bool)
+		for _, answer := range strings.Split(q.Answer, "┋") {
+			if selected[answer] {
+				continue
+			}
+			selected[answer] = true
+			option, err := d.GetOptionByQIDAndAnswer(ctx, question.ID, answer)
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				// 其他选项无需占用名额
+				continue
+			}
+			if err != nil {
+				return nil, nil, err
+			}
+			if option.Capacity == 0 {
+				continue
+			}
+			keys = append(keys, capacityKey(sid, QuestionKey(*question), option.SerialNum))
+			capacities = append(capacities, option.Capacity)
+			questions = append(questions, question)
+		}
+	}
+	if len(keys) == 0 {
+		return keys, nil, nil
+	}
+	index, err := reserveScript.Run(ctx, redis.RedisClient, keys, capacities...).Int()
+	if err != nil {
+		return nil, nil, err
+	}
+	if index != 0 {
+		return nil, questions[index-1], ErrOptionFull
+	}
+	return keys, nil, nil
+}
+
+// ReleaseOptionCapacity 释放已占用的选项名额
+func ReleaseOptionCapacity(keys []string) error {
+	return releaseCounters(keys)
+}
+
+// releaseCounters 将已占用的计数逐个减一
+func releaseCounters(keys []string) error {
+	for _, key := range keys {
+		if err := redis.RedisClient.Decr(ctx, key).Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetOptionRemaining 获取限额选项的剩余名额，返回选项ID到剩余名额的映射
+func GetOptionRemaining(sid int64, question model.Question, options []model.Option) (map[int]int, error) {
+	remaining := make(map[int]int)
+	for _, option := range options {
+		if option.Capacity == 0 || option.IsColumn {
+			continue
+		}
+		used, err := redis.RedisClient.Get(ctx, capacityKey(sid, QuestionKey(question), option.SerialNum)).Int()
+		if err != nil && !errors.Is(err, redisPkg.Nil) {
+			return nil, err
+		}
+		remaining[option.ID] = max(option.Capacity-used, 0)
+	}
+	return remaining, nil
+}
+
+// releaseAnswerSheetCapacity 释放答卷占用的选项名额
+func releaseAnswerSheetCapacity(answerSheet *dao.AnswerSheet) error {
+	return releaseCounters(answerSheetCapacityKeys(answerSheet))
+}
+
+// answerSheetCapacityKeys 获取答卷占用的选项名额计数的键
+func answerSheetCapacityKeys(answerSheet *dao.AnswerSheet) []string {
+	keys := make([]string, 0)
+	for _, answer := range answerSheet.Answers {
+		question, err := d.GetQuestionByID(ctx, answer.QuestionID)
+		if err != nil {
+			// 原问题已被删除时没有可释放的名额
+			continue
+		}
+		if (question.QuestionType != 1 && question.QuestionType != 2) || answer.Content == "" {
+			continue
+		}
+		selected := make(map[string]bool)
+		for _, content := range strings.Split(answer.Content, "┋") {
+			if selected[content] {
+				continue
+			}
+			selected[content] = true
+			option, err := d.GetOptionByQIDAndAnswer(ctx, question.ID, content)
+			if err != nil || option.Capacity == 0 {
+				continue
+			}
+			keys = append(keys, capacityKey(answerSheet.SurveyID, QuestionKey(*question), option.SerialNum))
+		}
+	}
+	return keys
+}
+
+// restoreCounters 将计数逐个加一，用于撤销释放操作
+func restoreCounters(keys []string) error {
+	for _, key := range keys {
+		if err := redis.RedisClient.Incr(ctx, key).Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteOptionCapacity 删除问卷所有选项名额计数
+func deleteOptionCapacity(sid int64) error {
+	return deleteKeys(fmt.Sprintf("capacity:sid:%d:*", sid))
+}
+
+// deleteKeys 删除匹配的所有键
+func deleteKeys(pattern string) error {
+	var cursor uint64
+	for {
+		keys, nextCursor, err := redis.RedisClient.Scan(ctx, cursor, pattern, 100).Result()
+		if err != nil {
+			return err
+		}
+		if len(keys) > 0 {
+			if err := redis.RedisClient.Del(ctx, keys...).Err(); err != nil {
+				return err
+			}
+		}
+		if nextCursor == 0 {
+			return nil
+		}
+		cursor = nextCursor
+	}
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"QA-System/internal/dao"
+	"QA-System/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// optionDaos 在 questionDaos 的基础上提供按答案获取选项
+type optionDaos struct {
+	questionDaos
+	options map[int][]model.Option
+}
+
+func (o *optionDaos) GetOptionByQIDAndAnswer(_ context.Context, qid int, answer string) (*model.Option, error) {
+	for _, option := range o.options[qid] {
+		if option.Content == answer {
+			return &option, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func TestAnswerSheetCapacityKeys(t *testing.T) {
+	original := d
+	d = &optionDaos{
+		questionDaos: questionDaos{questions: map[int]model.Question{
+			1: {ID: 1, Key: "a", QuestionType: 2},
+			2: {ID: 2, Key: "b", QuestionType: 3},
+		}},
+		options: map[int][]model.Option{
+			1: {{SerialNum: 1, Content: "A", Capacity: 1}, {SerialNum: 2, Content: "B"}},
+		},
+	}
+	defer func() { d = original }()
+
+	tests := []struct {
+		name    string
+		answers []dao.Answer
+		want    []string
+	}{
+		{"限额选项", []dao.Answer{{QuestionID: 1, Content: "A┋B"}}, []string{"capacity:sid:1:q:a:o:1"}},
+		{"重复选择只释放一个名额", []dao.Answer{{QuestionID: 1, Content: "A┋A"}}, []string{"capacity:sid:1:q:a:o:1"}},
+		{"不限额选项和其他选项", []dao.Answer{{QuestionID: 1, Content: "B┋自定义"}}, []string{}},
+		{"非选择题", []dao.Answer{{QuestionID: 2, Content: "A"}}, []string{}},
+		{"已删除的题目", []dao.Answer{{QuestionID: 3, Content: "A"}}, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := answerSheetCapacityKeys(&dao.AnswerSheet{SurveyID: 1, Answers: tt.answers})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("answerSheetCapacityKeys() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
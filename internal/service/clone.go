@@ -0,0 +1,165 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"QA-System/internal/dao"
+	global "QA-System/internal/global/config"
+	"QA-System/internal/model"
+	"QA-System/internal/pkg/oss"
+
+	"github.com/dustin/go-humanize"
+	"go.uber.org/zap"
+)
+
+var imageClient = &http.Client{Timeout: 30 * time.Second}
+
+// maxImageSize 复制图片的最大字节数，与上传图片的限制一致
+const maxImageSize = 10 * humanize.MiByte
+
+// ErrImageURL 图片地址带有对象键但不是本系统对象存储的地址
+var ErrImageURL = errors.New("图片地址不属于对象存储")
+
+// CloneSurvey 深拷贝问卷及其题目、选项和图片，生成归属于 uid 的未发布问卷，返回新问卷的ID
+// startTime 不为零值时，新问卷的开始时间与截止时间整体平移，保持原有的作答时长
+func CloneSurvey(survey *model.Survey, uid int, title string, startTime time.Time) (int64, error) {
+	definition, err := GetSurveyDefinition(survey)
+	if err != nil {
+		return 0, err
+	}
+	if title != "" {
+		definition.QuestionConfig.Title = title
+	}
+	if !startTime.IsZero() {
+		location := time.FixedZone("CST", 8*60*60)
+		deadline := startTime.Add(survey.Deadline.Sub(survey.StartTime))
+		definition.BaseConfig.StartTime = startTime.In(location).Format(time.RFC3339)
+		definition.BaseConfig.EndTime = deadline.In(location).Format(time.RFC3339)
+	}
+	return createSurveyWithImages(uid, definition)
+}
+
+// createSurveyWithImages 复制问卷定义中的图片后创建问卷，失败时删除已复制的图片
+func createSurveyWithImages(uid int, definition dao.SurveyDefinition) (int64, error) {
+	copied, err := copySurveyImages(definition.QuestionConfig.QuestionList)
+	if err != nil {
+		deleteImages(copied)
+		return 0, err
+	}
+	sid, err := CreateSurveyByDefinition(uid, definition)
+	if err != nil {
+		deleteImages(copied)
+		return 0, err
+	}
+	return sid, nil
+}
+
+// copySurveyImages 为题目和选项中的图片生成新的对象存储副本，并替换为新地址
+// 返回已上传的新图片地址，便于失败时清理
+func copySurveyImages(questionList []dao.QuestionList) ([]string, error) {
+	copies := make(map[string]string)
+	copied := make([]string, 0)
+	copyImage := func(url string) (string, error) {
+		if url == "" {
+			return "", nil
+		}
+		if newURL, ok := copies[url]; ok {
+			return newURL, nil
+		}
+		newURL, err := copyOSSImage(url)
+		if err != nil {
+			return "", err
+		}
+		copies[url] = newURL
+		if newURL != url {
+			copied = append(copied, newURL)
+		}
+		return newURL, nil
+	}
+	var err error
+	for i := range questionList {
+		question := &questionList[i]
+		if question.Img, err = copyImage(question.Img); err != nil {
+			return copied, err
+		}
+		for j := range question.Options {
+			if question.Options[j].Img, err = copyImage(question.Options[j].Img); err != nil {
+				return copied, err
+			}
+		}
+		for j := range question.Columns {
+			if question.Columns[j].Img, err = copyImage(question.Columns[j].Img); err != nil {
+				return copied, err
+			}
+		}
+	}
+	return copied, nil
+}
+
+// copyOSSImage 下载对象存储中的图片并以新的对象键重新上传，不带对象键的外部地址原样返回
+// 只下载本系统对象存储的地址，其他带对象键的地址返回 ErrImageURL
+func copyOSSImage(imageURL string) (string, error) {
+	objectKey := oss.Client.GetObjectKeyFromUrl(imageURL)
+	if objectKey == "" {
+		return imageURL, nil
+	}
+	if !isOSSFileURL(imageURL) {
+		return "", ErrImageURL
+	}
+	resp, err := imageClient.Get(imageURL)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			zap.L().Error("关闭图片响应失败", zap.Error(err))
+		}
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("下载图片失败: %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxImageSize+1))
+	if err != nil {
+		return "", err
+	}
+	if len(data) > maxImageSize {
+		return "", errors.New("图片大小超出限制")
+	}
+	result, err := oss.Client.UploadFile(path.Base(objectKey), bytes.NewReader(data), "img", false, true)
+	if err != nil {
+		return "", err
+	}
+	return oss.Client.GetFileURL(result.Data.ObjectKey, false), nil
+}
+
+// isOSSFileURL 判断地址是否为本系统对象存储的文件地址，协议、主机和路径需与配置一致
+func isOSSFileURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	base, err := url.Parse(global.Config.GetString("cube.baseUrl"))
+	if err != nil || base.Host == "" {
+		return false
+	}
+	return strings.EqualFold(u.Scheme, base.Scheme) && strings.EqualFold(u.Host, base.Host) &&
+		u.Path == strings.TrimRight(base.Path, "/")+"/api/file"
+}
+
+// deleteImages 删除对象存储中的图片
+func deleteImages(urls []string) {
+	for _, url := range urls {
+		_, err := oss.Client.DeleteFile(oss.Client.GetObjectKeyFromUrl(url))
+		if err != nil {
+			zap.L().Error("删除图片失败", zap.String("url", url), zap.Error(err))
+		}
+	}
+}
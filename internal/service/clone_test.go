@@ -0,0 +1,35 @@
+package service
+
+import (
+	"testing"
+
+	global "QA-System/internal/global/config"
+)
+
+func TestIsOSSFileURL(t *testing.T) {
+	original := global.Config.Get("cube.baseUrl")
+	global.Config.Set("cube.baseUrl", "https://oss.example.com/cube/")
+	defer global.Config.Set("cube.baseUrl", original)
+
+	tests := []struct {
+		name string
+		url  string
+		want bool
+	}{
+		{"对象存储地址", "https://oss.example.com/cube/api/file?bucket=qa&object_key=img/a.png", true},
+		{"主机大小写不同", "https://OSS.example.com/cube/api/file?object_key=a", true},
+		{"协议不同", "http://oss.example.com/cube/api/file?object_key=a", false},
+		{"主机不同", "https://169.254.169.254/cube/api/file?object_key=a", false},
+		{"端口不同", "https://oss.example.com:8080/cube/api/file?object_key=a", false},
+		{"路径不同", "https://oss.example.com/other/api/file?object_key=a", false},
+		{"用户信息伪装主机", "https://oss.example.com@127.0.0.1/cube/api/file?object_key=a", false},
+		{"无法解析", "://bad", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isOSSFileURL(tt.url); got != tt.want {
+				t.Errorf("isOSSFileURL(%q) = %v, want %v", tt.url, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,306 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"QA-System/internal/dao"
+	"QA-System/internal/model"
+
+	"github.com/zjutjh/WeJH-SDK/excel"
+)
+
+// ErrCrossTabDimension 交叉分析的维度不是选择题或答题者信息
+var ErrCrossTabDimension = errors.New("交叉分析的维度必须为选择题或答题者信息")
+
+// crossTabFields 可用于交叉分析的答题者信息字段
+var crossTabFields = map[string]string{
+	"college":        "学院",
+	"gender":         "性别",
+	"user_type_desc": "用户类型",
+}
+
+// CrossTabDimension 交叉分析的维度，SerialNum 为选择题序号，Field 为答题者信息字段，二者取其一
+type CrossTabDimension struct {
+	SerialNum int
+	Field     string
+}
+
+// CrossTabCategory 交叉分析的行或列
+type CrossTabCategory struct {
+	Key   string `json:"key"`   // 选项序号或答题者信息取值
+	Label string `json:"label"` // 显示名称
+	Total int    `json:"total"` // 该行或该列的答卷数量
+}
+
+// CrossTabCell 交叉分析的单元格
+type CrossTabCell struct {
+	Count         int    `json:"count"`          // 同时满足行和列的答卷数量
+	RowPercent    string `json:"row_percent"`    // 占该行答卷的百分比
+	ColumnPercent string `json:"column_percent"` // 占该列答卷的百分比
+}
+
+// CrossTabResponse 交叉分析结果
+type CrossTabResponse struct {
+	RowTitle    string             `json:"row_title"`    // 行维度名称
+	ColumnTitle string             `json:"column_title"` // 列维度名称
+	Rows        []CrossTabCategory `json:"rows"`         // 行
+	Columns     []CrossTabCategory `json:"columns"`      // 列
+	Cells       [][]CrossTabCell   `json:"cells"`        // 单元格，按行、列排列
+	Total       int                `json:"total"`        // 参与分析的答卷数量
+}
+
+// crossTabAxis 交叉分析的一个维度，values 返回答卷在该维度上的取值
+type crossTabAxis struct {
+	title      string
+	categories []CrossTabCategory
+	values     func(sheet dao.AnswerSheet) []string
+}
+
+// CrossTabulate 对问卷的两个维度进行交叉分析
+// 多选题的一份答卷可计入多行或多列，行列的合计按答卷数量计算
+func CrossTabulate(survey *model.Survey, row, column CrossTabDimension) (*CrossTabResponse, error) {
+	questions, err := d.GetQuestionsBySurveyID(ctx, survey.ID)
+	if err != nil {
+		return nil, err
+	}
+	answerSheets, err := GetSurveyAnswersBySurveyID(survey.ID)
+	if err != nil {
+		return nil, err
+	}
+	var records map[string]dao.RecordSheet
+	if row.Field != "" || column.Field != "" {
+		sheets, err := d.GetRecordSheetsBySurveyID(ctx, survey.ID)
+		if err != nil {
+			return nil, err
+		}
+		records = make(map[string]dao.RecordSheet, len(sheets))
+		for _, record := range sheets {
+			records[record.AnswerID.Hex()] = record
+		}
+	}
+	rowAxis, err := getCrossTabAxis(row, questions, records)
+	if err != nil {
+		return nil, err
+	}
+	columnAxis, err := getCrossTabAxis(column, questions, records)
+	if err != nil {
+		return nil, err
+	}
+	rowValues := make([][]string, len(answerSheets))
+	columnValues := make([][]string, len(answerSheets))
+	for i, sheet := range answerSheets {
+		rowValues[i] = rowAxis.values(sheet)
+		columnValues[i] = columnAxis.values(sheet)
+	}
+	// 答题者信息的取值在统计前按名称排序
+	if row.Field != "" {
+		rowAxis.categories = getFieldCategories(rowValues)
+	}
+	if column.Field != "" {
+		columnAxis.categories = getFieldCategories(columnValues)
+	}
+	rowIndex := getCategoryIndex(rowAxis.categories)
+	columnIndex := getCategoryIndex(columnAxis.categories)
+
+	counts := make([][]int, len(rowAxis.categories))
+	for i := range counts {
+		counts[i] = make([]int, len(columnAxis.categories))
+	}
+	total := 0
+	for i := range answerSheets {
+		if len(rowValues[i]) == 0 || len(columnValues[i]) == 0 {
+			continue
+		}
+		total++
+		for _, r := range rowValues[i] {
+			rowAxis.categories[rowIndex[r]].Total++
+		}
+		for _, c := range columnValues[i] {
+			columnAxis.categories[columnIndex[c]].Total++
+		}
+		for _, r := range rowValues[i] {
+			for _, c := range columnValues[i] {
+				counts[rowIndex[r]][columnIndex[c]]++
+			}
+		}
+	}
+
+	cells := make([][]CrossTabCell, len(rowAxis.categories))
+	for i, r := range rowAxis.categories {
+		cells[i] = make([]CrossTabCell, len(columnAxis.categories))
+		for j, c := range columnAxis.categories {
+			cells[i][j] = CrossTabCell{
+				Count:         counts[i][j],
+				RowPercent:    formatPercent(counts[i][j], r.Total),
+				ColumnPercent: formatPercent(counts[i][j], c.Total),
+			}
+		}
+	}
+	return &CrossTabResponse{
+		RowTitle:    rowAxis.title,
+		ColumnTitle: columnAxis.title,
+		Rows:        rowAxis.categories,
+		Columns:     columnAxis.categories,
+		Cells:       cells,
+		Total:       total,
+	}, nil
+}
+
+// getCrossTabAxis 根据维度生成交叉分析的行或列
+func getCrossTabAxis(dimension CrossTabDimension, questions []model.Question,
+	records map[string]dao.RecordSheet) (*crossTabAxis, error) {
+	if dimension.Field != "" {
+		title, ok := crossTabFields[dimension.Field]
+		if !ok {
+			return nil, ErrCrossTabDimension
+		}
+		return &crossTabAxis{
+			title: title,
+			values: func(sheet dao.AnswerSheet) []string {
+				record, ok := records[sheet.AnswerID.Hex()]
+				if !ok {
+					return nil
+				}
+				value := getRecordField(record, dimension.Field)
+				if value == "" {
+					value = "未知"
+				}
+				return []string{value}
+			},
+		}, nil
+	}
+	var question *model.Question
+	for i := range questions {
+		if questions[i].SerialNum == dimension.SerialNum {
+			question = &questions[i]
+			break
+		}
+	}
+	if question == nil || (question.QuestionType != 1 && question.QuestionType != 2) {
+		return nil, ErrCrossTabDimension
+	}
+	options, err := d.GetOptionsByQuestionID(ctx, question.ID)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(options, func(i, j int) bool {
+		return options[i].SerialNum < options[j].SerialNum
+	})
+	axis := &crossTabAxis{
+		title:      fmt.Sprintf("第%d题 %s", question.SerialNum, question.Subject),
+		categories: make([]CrossTabCategory, 0, len(options)+1),
+	}
+	contentMap := make(map[string]string)
+	for _, option := range options {
+		key := strconv.Itoa(option.SerialNum)
+		contentMap[option.Content] = key
+		axis.categories = append(axis.categories, CrossTabCategory{Key: key, Label: option.Content})
+	}
+	// “其他”选项统一用序号 0 表示
+	if question.OtherOption {
+		axis.categories = append(axis.categories, CrossTabCategory{Key: "0", Label: "其他"})
+	}
+	axis.values = func(sheet dao.AnswerSheet) []string {
+		for _, answer := range sheet.Answers {
+			if answer.QuestionID != question.ID || answer.Content == "" {
+				continue
+			}
+			values := make([]string, 0)
+			seen := make(map[string]bool)
+			for _, content := range strings.Split(answer.Content, "┋") {
+				key, ok := contentMap[content]
+				if !ok {
+					if !question.OtherOption {
+						continue
+					}
+					key = "0"
+				}
+				if !seen[key] {
+					seen[key] = true
+					values = append(values, key)
+				}
+			}
+			return values
+		}
+		return nil
+	}
+	return axis, nil
+}
+
+// getFieldCategories 根据答卷的取值生成答题者信息的行或列
+func getFieldCategories(values [][]string) []CrossTabCategory {
+	seen := make(map[string]bool)
+	keys := make([]string, 0)
+	for _, sheetValues := range values {
+		for _, value := range sheetValues {
+			if !seen[value] {
+				seen[value] = true
+				keys = append(keys, value)
+			}
+		}
+	}
+	sort.Strings(keys)
+	categories := make([]CrossTabCategory, 0, len(keys))
+	for _, key := range keys {
+		categories = append(categories, CrossTabCategory{Key: key, Label: key})
+	}
+	return categories
+}
+
+func getCategoryIndex(categories []CrossTabCategory) map[string]int {
+	index := make(map[string]int, len(categories))
+	for i, category := range categories {
+		index[category.Key] = i
+	}
+	return index
+}
+
+// formatPercent 计算百分比，保留两位小数
+func formatPercent(count, total int) string {
+	if total == 0 {
+		return "0.00%"
+	}
+	return fmt.Sprintf("%.2f%%", float64(count)*100/float64(total))
+}
+
+// HandleCrossTabStatistics 将交叉分析结果导出为 Excel 文件，分别包含计数、行百分比和列百分比
+func HandleCrossTabStatistics(survey *model.Survey, tab *CrossTabResponse) (string, error) {
+	headers := []string{tab.RowTitle + " \\ " + tab.ColumnTitle}
+	for _, column := range tab.Columns {
+		headers = append(headers, column.Label)
+	}
+	headers = append(headers, "合计")
+	countRows := make([][]any, 0, len(tab.Rows)+1)
+	rowPercentRows := make([][]any, 0, len(tab.Rows))
+	columnPercentRows := make([][]any, 0, len(tab.Rows))
+	for i, r := range tab.Rows {
+		countRow := []any{r.Label}
+		rowPercentRow := []any{r.Label}
+		columnPercentRow := []any{r.Label}
+		for _, cell := range tab.Cells[i] {
+			countRow = append(countRow, cell.Count)
+			rowPercentRow = append(rowPercentRow, cell.RowPercent)
+			columnPercentRow = append(columnPercentRow, cell.ColumnPercent)
+		}
+		countRows = append(countRows, append(countRow, r.Total))
+		rowPercentRows = append(rowPercentRows, rowPercentRow)
+		columnPercentRows = append(columnPercentRows, columnPercentRow)
+	}
+	totalRow := []any{"合计"}
+	for _, column := range tab.Columns {
+		totalRow = append(totalRow, column.Total)
+	}
+	countRows = append(countRows, append(totalRow, tab.Total))
+
+	percentHeaders := headers[:len(headers)-1]
+	fileData := excel.File{Sheets: []excel.Sheet{
+		{Name: "计数", Headers: headers, Rows: countRows},
+		{Name: "行百分比", Headers: percentHeaders, Rows: rowPercentRows},
+		{Name: "列百分比", Headers: percentHeaders, Rows: columnPercentRows},
+	}}
+	return saveExcelFile(fileData, survey.Title+"_交叉分析.xlsx")
+}
@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"QA-System/internal/dao"
+	"QA-System/internal/model"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type crossTabDaos struct {
+	optionDaos
+	surveyQuestions []model.Question
+	answerSheets    []dao.AnswerSheet
+	records         []dao.RecordSheet
+}
+
+func (c *crossTabDaos) GetQuestionsBySurveyID(_ context.Context, _ int64) ([]model.Question, error) {
+	return c.surveyQuestions, nil
+}
+
+func (c *crossTabDaos) GetAnswerSheetBySurveyID(
+	_ context.Context, _ int64, _ int, _ int, _ string, _ bool) ([]dao.AnswerSheet, *int64, error) {
+	sheets := make([]dao.AnswerSheet, len(c.answerSheets))
+	copy(sheets, c.answerSheets)
+	total := int64(len(sheets))
+	return sheets, &total, nil
+}
+
+func (c *crossTabDaos) GetRecordSheetsBySurveyID(_ context.Context, _ int64) ([]dao.RecordSheet, error) {
+	return c.records, nil
+}
+
+func (c *crossTabDaos) GetOptionsByQuestionID(_ context.Context, questionID int) ([]model.Option, error) {
+	return c.options[questionID], nil
+}
+
+func TestCrossTabulate(t *testing.T) {
+	// 题目1为单选题，题目2为带“其他”选项的多选题，题目3为填空题
+	ids := []primitive.ObjectID{
+		primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID(),
+	}
+	fake := &crossTabDaos{
+		optionDaos: optionDaos{options: map[int][]model.Option{
+			1: {{SerialNum: 2, Content: "大二"}, {SerialNum: 1, Content: "大一"}},
+			2: {{SerialNum: 1, Content: "篮球"}, {SerialNum: 2, Content: "足球"}, {SerialNum: 3, Content: "羽毛球"}},
+		}},
+		surveyQuestions: []model.Question{
+			{ID: 1, SerialNum: 1, Subject: "年级", QuestionType: 1},
+			{ID: 2, SerialNum: 2, Subject: "运动", QuestionType: 2, OtherOption: true},
+			{ID: 3, SerialNum: 3, Subject: "建议", QuestionType: 3},
+		},
+		// 第4份答卷未作答题目2，不参与统计
+		answerSheets: []dao.AnswerSheet{
+			{AnswerID: ids[0], Answers: []dao.Answer{
+				{QuestionID: 1, Content: "大一"}, {QuestionID: 2, Content: "篮球┋足球"},
+			}},
+			{AnswerID: ids[1], Answers: []dao.Answer{
+				{QuestionID: 1, Content: "大一"}, {QuestionID: 2, Content: "足球"},
+			}},
+			{AnswerID: ids[2], Answers: []dao.Answer{
+				{QuestionID: 1, Content: "大二"}, {QuestionID: 2, Content: "篮球┋游泳"},
+			}},
+			{AnswerID: ids[3], Answers: []dao.Answer{
+				{QuestionID: 1, Content: "大二"}, {QuestionID: 2, Content: ""},
+			}},
+		},
+		records: []dao.RecordSheet{
+			{AnswerID: ids[0], College: "计算机学院", UserType: "undergraduate"},
+			{AnswerID: ids[1], College: "理学院", UserType: "undergraduate"},
+			{AnswerID: ids[2], UserType: "graduate"},
+			{AnswerID: ids[3], College: "计算机学院", UserType: "graduate"},
+		},
+	}
+	original := d
+	d = fake
+	defer func() { d = original }()
+
+	sportColumns := []CrossTabCategory{
+		{Key: "1", Label: "篮球", Total: 2},
+		{Key: "2", Label: "足球", Total: 2},
+		{Key: "3", Label: "羽毛球", Total: 0},
+		{Key: "0", Label: "其他", Total: 1},
+	}
+	tests := []struct {
+		name        string
+		row         CrossTabDimension
+		column      CrossTabDimension
+		wantErr     error
+		rowTitle    string
+		columnTitle string
+		rows        []CrossTabCategory
+		columns     []CrossTabCategory
+		counts      [][]int
+		total       int
+	}{
+		{
+			name:        "单选题与多选题交叉",
+			row:         CrossTabDimension{SerialNum: 1},
+			column:      CrossTabDimension{SerialNum: 2},
+			rowTitle:    "第1题 年级",
+			columnTitle: "第2题 运动",
+			rows:        []CrossTabCategory{{Key: "1", Label: "大一", Total: 2}, {Key: "2", Label: "大二", Total: 1}},
+			columns:     sportColumns,
+			counts:      [][]int{{1, 2, 0, 0}, {1, 0, 0, 1}},
+			total:       3,
+		},
+		{
+			name:        "多选题与自身交叉",
+			row:         CrossTabDimension{SerialNum: 2},
+			column:      CrossTabDimension{SerialNum: 2},
+			rowTitle:    "第2题 运动",
+			columnTitle: "第2题 运动",
+			rows:        sportColumns,
+			columns:     sportColumns,
+			counts:      [][]int{{2, 1, 0, 1}, {1, 2, 0, 0}, {0, 0, 0, 0}, {1, 0, 0, 1}},
+			total:       3,
+		},
+		{
+			name:        "答题者信息与多选题交叉",
+			row:         CrossTabDimension{Field: "college"},
+			column:      CrossTabDimension{SerialNum: 2},
+			rowTitle:    "学院",
+			columnTitle: "第2题 运动",
+			rows: []CrossTabCategory{
+				{Key: "未知", Label: "未知", Total: 1},
+				{Key: "理学院", Label: "理学院", Total: 1},
+				{Key: "计算机学院", Label: "计算机学院", Total: 1},
+			},
+			columns: sportColumns,
+			counts:  [][]int{{1, 0, 0, 1}, {0, 1, 0, 0}, {1, 1, 0, 0}},
+			total:   3,
+		},
+		{
+			name:    "不支持的答题者信息字段",
+			row:     CrossTabDimension{Field: "user_type"},
+			column:  CrossTabDimension{SerialNum: 1},
+			wantErr: ErrCrossTabDimension,
+		},
+		{
+			name:    "非选择题",
+			row:     CrossTabDimension{SerialNum: 1},
+			column:  CrossTabDimension{SerialNum: 3},
+			wantErr: ErrCrossTabDimension,
+		},
+		{
+			name:    "题目不存在",
+			row:     CrossTabDimension{SerialNum: 4},
+			column:  CrossTabDimension{SerialNum: 1},
+			wantErr: ErrCrossTabDimension,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CrossTabulate(&model.Survey{ID: 1}, tt.row, tt.column)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CrossTabulate() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if got.RowTitle != tt.rowTitle || got.ColumnTitle != tt.columnTitle {
+				t.Errorf("CrossTabulate() titles = %q, %q, want %q, %q",
+					got.RowTitle, got.ColumnTitle, tt.rowTitle, tt.columnTitle)
+			}
+			if !reflect.DeepEqual(got.Rows, tt.rows) {
+				t.Errorf("CrossTabulate() rows = %v, want %v", got.Rows, tt.rows)
+			}
+			if !reflect.DeepEqual(got.Columns, tt.columns) {
+				t.Errorf("CrossTabulate() columns = %v, want %v", got.Columns, tt.columns)
+			}
+			counts := make([][]int, len(got.Cells))
+			for i, row := range got.Cells {
+				counts[i] = make([]int, len(row))
+				for j, cell := range row {
+					counts[i][j] = cell.Count
+				}
+			}
+			if !reflect.DeepEqual(counts, tt.counts) {
+				t.Errorf("CrossTabulate() counts = %v, want %v", counts, tt.counts)
+			}
+			if got.Total != tt.total {
+				t.Errorf("CrossTabulate() total = %d, want %d", got.Total, tt.total)
+			}
+		})
+	}
+
+	t.Run("百分比按行列合计计算", func(t *testing.T) {
+		row, column := CrossTabDimension{SerialNum: 1}, CrossTabDimension{SerialNum: 2}
+		got, err := CrossTabulate(&model.Survey{ID: 1}, row, column)
+		if err != nil {
+			t.Fatalf("CrossTabulate() error = %v", err)
+		}
+		want := []CrossTabCell{
+			{Count: 1, RowPercent: "50.00%", ColumnPercent: "50.00%"},
+			{Count: 2, RowPercent: "100.00%", ColumnPercent: "100.00%"},
+			{Count: 0, RowPercent: "0.00%", ColumnPercent: "0.00%"},
+			{Count: 0, RowPercent: "0.00%", ColumnPercent: "0.00%"},
+		}
+		if !reflect.DeepEqual(got.Cells[0], want) {
+			t.Errorf("CrossTabulate() first row = %v, want %v", got.Cells[0], want)
+		}
+	})
+}
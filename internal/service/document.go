@@ -0,0 +1,20 @@
+package service
+
+import (
+	"QA-System/internal/dao"
+	"QA-System/internal/model"
+)
+
+// ExportSurvey 导出问卷定义文档
+func ExportSurvey(survey *model.Survey) (dao.SurveyDocument, error) {
+	definition, err := GetSurveyDefinition(survey)
+	if err != nil {
+		return dao.SurveyDocument{}, err
+	}
+	return dao.SurveyDocument{Version: dao.SurveyDocumentVersion, SurveyDefinition: definition}, nil
+}
+
+// ImportSurvey 根据问卷定义文档创建归属于 uid 的未发布问卷，文档中的图片重新上传，返回新问卷的ID
+func ImportSurvey(uid int, document dao.SurveyDocument) (int64, error) {
+	return createSurveyWithImages(uid, document.SurveyDefinition)
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"QA-System/internal/dao"
+	"QA-System/internal/model"
+	"QA-System/internal/pkg/redis"
+
+	redisPkg "github.com/redis/go-redis/v9"
+)
+
+// defaultDraftTTL 问卷未设置截止时间时草稿的保存时长
+const defaultDraftTTL = 30 * 24 * time.Hour
+
+// maxDraftSize 草稿序列化后的最大字节数
+const maxDraftSize = 64 * 1024
+
+// ErrDraftTooLarge 草稿内容超出长度限制
+var ErrDraftTooLarge = errors.New("草稿内容过长")
+
+// resumeTokenPattern 续填令牌格式，与 NewResumeToken 生成的令牌一致
+var resumeTokenPattern = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+// Draft 答卷草稿
+type Draft struct {
+	QuestionsList []dao.QuestionsList `json:"questions_list"` // 已填写的答案
+	Time          string              `json:"time"`           // 保存时间
+}
+
+// draftKey 草稿的键，统一验证的问卷使用学号，否则使用续填令牌
+func draftKey(sid int64, studentID string, resumeToken string) string {
+	if studentID != "" {
+		return fmt.Sprintf("draft:sid:%d:stu:%s", sid, studentID)
+	}
+	return fmt.Sprintf("draft:sid:%d:token:%s", sid, resumeToken)
+}
+
+// NewResumeToken 生成续填令牌
+func NewResumeToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// ValidResumeToken 判断续填令牌格式是否合法
+func ValidResumeToken(token string) bool {
+	return resumeTokenPattern.MatchString(token)
+}
+
+// SaveDraft 保存答卷草稿，草稿在问卷截止时自动过期，内容过长时返回 ErrDraftTooLarge
+func SaveDraft(survey *model.Survey, studentID string, resumeToken string, data []dao.QuestionsList) (string, error) {
+	draft := Draft{
+		QuestionsList: data,
+		Time:          time.Now().Format(time.DateTime),
+	}
+	jsonData, err := json.Marshal(draft)
+	if err != nil {
+		return "", err
+	}
+	if len(jsonData) > maxDraftSize {
+		return "", ErrDraftTooLarge
+	}
+	ttl := defaultDraftTTL
+	if !survey.Deadline.IsZero() {
+		ttl = time.Until(survey.Deadline)
+	}
+	if ttl <= 0 {
+		return "", errors.New("问卷填写时间已截至")
+	}
+	err = redis.RedisClient.Set(ctx, draftKey(survey.ID, studentID, resumeToken), jsonData, ttl).Err()
+	return draft.Time, err
+}
+
+// GetDraft 获取答卷草稿，草稿不存在时返回 nil
+func GetDraft(sid int64, studentID string, resumeToken string) (*Draft, error) {
+	cachedData, err := redis.RedisClient.Get(ctx, draftKey(sid, studentID, resumeToken)).Result()
+	if errors.Is(err, redisPkg.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var draft Draft
+	if err := json.Unmarshal([]byte(cachedData), &draft); err != nil {
+		return nil, err
+	}
+	return &draft, nil
+}
+
+// DeleteDraft 删除答卷草稿
+func DeleteDraft(sid int64, studentID string, resumeToken string) error {
+	if studentID == "" && resumeToken == "" {
+		return nil
+	}
+	return redis.RedisClient.Del(ctx, draftKey(sid, studentID, resumeToken)).Err()
+}
+
+// deleteDrafts 删除问卷所有答卷草稿
+func deleteDrafts(sid int64) error {
+	return deleteKeys(fmt.Sprintf("draft:sid:%d:*", sid))
+}
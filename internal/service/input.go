@@ -0,0 +1,77 @@
+package service
+
+import (
+	"math"
+	"net/mail"
+	"regexp"
+	"strconv"
+	"time"
+
+	"QA-System/internal/model"
+	"QA-System/internal/pkg/code"
+)
+
+var (
+	phoneReg     = regexp.MustCompile(`^1[3-9]\d{9}$`)
+	studentIDReg = regexp.MustCompile(`^\d{12}$`)
+)
+
+// CheckInputAnswer 检查填空题和简答题答案的格式，答案合法时返回 nil
+func CheckInputAnswer(question *model.Question, answer string) *code.Error {
+	if answer == "" {
+		return nil
+	}
+	if question.Reg != "" {
+		reg, err := regexp.Compile(question.Reg)
+		if err != nil || !reg.MatchString(answer) {
+			return code.AnswerFormatError
+		}
+	}
+	if question.QuestionType != 3 {
+		return nil
+	}
+	switch question.InputType {
+	case 1:
+		if _, err := time.Parse(time.DateOnly, answer); err != nil {
+			return code.AnswerDateError
+		}
+	case 2:
+		if _, err := time.Parse(time.DateTime, answer); err != nil {
+			if _, err := time.Parse("2006-01-02 15:04", answer); err != nil {
+				return code.AnswerDateError
+			}
+		}
+	case 3:
+		value, err := strconv.Atoi(answer)
+		if err != nil || !inBounds(question, float64(value)) {
+			return code.AnswerNumberError
+		}
+	case 4:
+		value, err := strconv.ParseFloat(answer, 64)
+		if err != nil || math.IsNaN(value) || math.IsInf(value, 0) || !inBounds(question, value) {
+			return code.AnswerNumberError
+		}
+	case 5:
+		address, err := mail.ParseAddress(answer)
+		if err != nil || address.Address != answer {
+			return code.AnswerEmailError
+		}
+	case 6:
+		if !phoneReg.MatchString(answer) {
+			return code.AnswerPhoneError
+		}
+	case 7:
+		if !studentIDReg.MatchString(answer) {
+			return code.AnswerStudentIDError
+		}
+	}
+	return nil
+}
+
+// inBounds 判断数值是否在题目设置的范围内，最小值和最大值均为 0 时不限制
+func inBounds(question *model.Question, value float64) bool {
+	if question.MinValue == 0 && question.MaxValue == 0 {
+		return true
+	}
+	return value >= question.MinValue && value <= question.MaxValue
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"testing"
+
+	"QA-System/internal/model"
+	"QA-System/internal/pkg/code"
+)
+
+func TestCheckInputAnswer(t *testing.T) {
+	input := func(inputType int, minValue, maxValue float64) *model.Question {
+		return &model.Question{QuestionType: 3, InputType: inputType, MinValue: minValue, MaxValue: maxValue}
+	}
+	tests := []struct {
+		name     string
+		question *model.Question
+		answer   string
+		want     *code.Error
+	}{
+		{"空答案", input(4, 1, 10), "", nil},
+		{"正则匹配", &model.Question{QuestionType: 3, Reg: `^\d+$`}, "123", nil},
+		{"正则不匹配", &model.Question{QuestionType: 3, Reg: `^\d+$`}, "12a", code.AnswerFormatError},
+		{"简答题不检查输入类型", &model.Question{QuestionType: 4, InputType: 3}, "abc", nil},
+		{"日期", input(1, 0, 0), "2024-02-29", nil},
+		{"日期格式错误", input(1, 0, 0), "2024/02/29", code.AnswerDateError},
+		{"日期时间不含秒", input(2, 0, 0), "2024-02-29 08:30", nil},
+		{"整数", input(3, 1, 10), "10", nil},
+		{"整数超出范围", input(3, 1, 10), "11", code.AnswerNumberError},
+		{"整数不接受小数", input(3, 0, 0), "1.5", code.AnswerNumberError},
+		{"小数", input(4, 0, 1), "0.5", nil},
+		{"小数不限范围", input(4, 0, 0), "-12.5", nil},
+		{"小数 NaN", input(4, 0, 0), "NaN", code.AnswerNumberError},
+		{"小数正无穷", input(4, 0, 0), "Inf", code.AnswerNumberError},
+		{"小数负无穷", input(4, 0, 0), "-Inf", code.AnswerNumberError},
+		{"小数溢出", input(4, 0, 0), "1e309", code.AnswerNumberError},
+		{"邮箱", input(5, 0, 0), "a@example.com", nil},
+		{"邮箱带名称", input(5, 0, 0), "A <a@example.com>", code.AnswerEmailError},
+		{"手机号", input(6, 0, 0), "13800138000", nil},
+		{"手机号位数错误", input(6, 0, 0), "1380013800", code.AnswerPhoneError},
+		{"学号", input(7, 0, 0), "202412345678", nil},
+		{"学号位数错误", input(7, 0, 0), "20241234567", code.AnswerStudentIDError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CheckInputAnswer(tt.question, tt.answer); got != tt.want {
+				t.Errorf("CheckInputAnswer(%q) = %v, want %v", tt.answer, got, tt.want)
+			}
+		})
+	}
+}
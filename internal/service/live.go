@@ -0,0 +1,255 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"QA-System/internal/dao"
+	"QA-System/internal/model"
+	"QA-System/internal/pkg/redis"
+
+	redisPkg "github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// liveChannelPrefix 投票实时结果的 Redis 频道前缀，完整频道为前缀加问卷ID
+const liveChannelPrefix = "live:survey:"
+
+// liveSeqKey 投票实时结果序号的键，每发布一次变化序号加一
+func liveSeqKey(sid int64) string {
+	return fmt.Sprintf("live:seq:%d", sid)
+}
+
+// LiveUpdate 投票问卷一次答卷变化带来的选项数量变化
+type LiveUpdate struct {
+	SurveyID  int64               `json:"survey_id"` // 问卷ID
+	Seq       int64               `json:"seq"`       // 问卷内递增的序号
+	Total     int                 `json:"total"`     // 答卷数量变化
+	Questions []LiveQuestionDelta `json:"questions"` // 各题目的选项数量变化
+}
+
+// LiveQuestionDelta 题目的选项数量变化
+type LiveQuestionDelta struct {
+	SerialNum int               `json:"serial_num"` // 问题序号
+	Options   []LiveOptionDelta `json:"options"`    // 选项数量变化
+}
+
+// LiveOptionDelta 选项的数量变化
+type LiveOptionDelta struct {
+	SerialNum int `json:"serial_num"` // 选项序号 0为“其他”
+	Delta     int `json:"delta"`      // 数量变化
+}
+
+// liveHub 本实例上订阅实时结果的连接，通过 Redis 订阅接收所有实例发布的变化
+type liveHub struct {
+	mu      sync.Mutex
+	once    sync.Once
+	clients map[int64]map[chan LiveUpdate]struct{}
+}
+
+var hub = &liveHub{clients: make(map[int64]map[chan LiveUpdate]struct{})}
+
+// SubscribeLiveResults 订阅投票问卷的实时结果，返回接收选项数量变化的通道和取消订阅的函数
+// 接收过慢导致通道堆积时通道会被关闭，客户端应重新连接以获取最新结果
+func SubscribeLiveResults(sid int64) (<-chan LiveUpdate, func()) {
+	hub.once.Do(func() {
+		go hub.listen()
+	})
+	ch := make(chan LiveUpdate, 64)
+	hub.mu.Lock()
+	if hub.clients[sid] == nil {
+		hub.clients[sid] = make(map[chan LiveUpdate]struct{})
+	}
+	hub.clients[sid][ch] = struct{}{}
+	hub.mu.Unlock()
+	return ch, func() {
+		hub.mu.Lock()
+		defer hub.mu.Unlock()
+		hub.remove(sid, ch)
+	}
+}
+
+// listen 订阅所有问卷的实时结果频道，并分发给本实例上的连接
+func (h *liveHub) listen() {
+	pubsub := redis.RedisClient.PSubscribe(ctx, liveChannelPrefix+"*")
+	for msg := range pubsub.Channel() {
+		var update LiveUpdate
+		if err := json.Unmarshal([]byte(msg.Payload), &update); err != nil {
+			zap.L().Error("解析实时结果失败", zap.String("channel", msg.Channel), zap.Error(err))
+			continue
+		}
+		h.dispatch(update)
+	}
+}
+
+func (h *liveHub) dispatch(update LiveUpdate) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.clients[update.SurveyID] {
+		select {
+		case ch <- update:
+		default:
+			h.remove(update.SurveyID, ch)
+		}
+	}
+}
+
+// remove 移除连接并关闭通道，调用方需持有锁
+func (h *liveHub) remove(sid int64, ch chan LiveUpdate) {
+	if _, ok := h.clients[sid][ch]; !ok {
+		return
+	}
+	delete(h.clients[sid], ch)
+	close(ch)
+	if len(h.clients[sid]) == 0 {
+		delete(h.clients, sid)
+	}
+}
+
+// GetLiveSequence 获取投票问卷已发布的实时结果的最新序号
+// 在读取完整结果之前获取，序号不大于该值的变化对应的答卷均已计入完整结果
+func GetLiveSequence(sid int64) (int64, error) {
+	seq, err := redis.RedisClient.Get(ctx, liveSeqKey(sid)).Int64()
+	if errors.Is(err, redisPkg.Nil) {
+		return 0, nil
+	}
+	return seq, err
+}
+
+// deleteLiveSequence 删除投票问卷的实时结果序号
+func deleteLiveSequence(sid int64) error {
+	return redis.RedisClient.Del(ctx, liveSeqKey(sid)).Err()
+}
+
+// publishLiveResult 发布投票问卷答卷变化带来的选项数量变化，失败只记录日志，不影响答卷提交
+// oldAnswers 为撤销的答案，newAnswers 为新增的答案，total 为答卷数量变化
+// 答卷保存后才分配序号，保证序号不大于完整结果序号的变化已计入完整结果
+func publishLiveResult(sid int64, oldAnswers, newAnswers []dao.Answer, total int) {
+	survey, err := d.GetSurveyByID(ctx, sid)
+	if err != nil {
+		zap.L().Error("获取问卷失败", zap.Int64("survey_id", sid), zap.Error(err))
+		return
+	}
+	if survey.Type != 1 {
+		return
+	}
+	update, err := buildLiveUpdate(sid, oldAnswers, newAnswers, total)
+	if err != nil {
+		zap.L().Error("生成实时结果失败", zap.Int64("survey_id", sid), zap.Error(err))
+		return
+	}
+	update.Seq, err = redis.RedisClient.Incr(ctx, liveSeqKey(sid)).Result()
+	if err != nil {
+		zap.L().Error("获取实时结果序号失败", zap.Int64("survey_id", sid), zap.Error(err))
+		return
+	}
+	payload, err := json.Marshal(update)
+	if err != nil {
+		zap.L().Error("序列化实时结果失败", zap.Int64("survey_id", sid), zap.Error(err))
+		return
+	}
+	err = redis.RedisClient.Publish(ctx, liveChannelPrefix+strconv.FormatInt(sid, 10), payload).Err()
+	if err != nil {
+		zap.L().Error("发布实时结果失败", zap.Int64("survey_id", sid), zap.Error(err))
+	}
+}
+
+// publishUniqueChanges 发布其他答卷唯一性变化带来的选项数量变化
+// 只有唯一的答卷计入统计，demoted 为不再唯一的答卷，promoted 为恢复为唯一的答卷
+func publishUniqueChanges(sid int64, demoted, promoted []dao.AnswerSheet) {
+	for _, sheet := range demoted {
+		publishLiveResult(sid, sheet.Answers, nil, -1)
+	}
+	for _, sheet := range promoted {
+		publishLiveResult(sid, nil, sheet.Answers, 1)
+	}
+}
+
+// buildLiveUpdate 按当前版本的选择题计算选项数量变化，与统计结果一致，不属于任何选项的答案计入“其他”
+func buildLiveUpdate(sid int64, oldAnswers, newAnswers []dao.Answer, total int) (LiveUpdate, error) {
+	update := LiveUpdate{SurveyID: sid, Total: total, Questions: make([]LiveQuestionDelta, 0)}
+	questions, err := d.GetQuestionsBySurveyID(ctx, sid)
+	if err != nil {
+		return update, err
+	}
+	currentMap := make(map[int]model.Question)
+	keyMap := make(map[string]model.Question)
+	for _, question := range questions {
+		currentMap[question.ID] = question
+		keyMap[QuestionKey(question)] = question
+	}
+	// 题目ID对应的选项序号对应的数量变化
+	deltas := make(map[int]map[int]int)
+	contentMaps := make(map[int]map[string]int)
+	apply := func(answers []dao.Answer, delta int) error {
+		for _, answer := range answers {
+			if answer.Content == "" {
+				continue
+			}
+			question, ok := currentMap[answer.QuestionID]
+			if !ok {
+				// 修改历史版本的答卷时按题目标识对应到当前版本的题目
+				old, err := d.GetQuestionByID(ctx, answer.QuestionID)
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					continue
+				} else if err != nil {
+					return err
+				}
+				if question, ok = keyMap[QuestionKey(*old)]; !ok {
+					continue
+				}
+			}
+			if question.QuestionType != 1 && question.QuestionType != 2 {
+				continue
+			}
+			contentMap, ok := contentMaps[question.ID]
+			if !ok {
+				options, err := d.GetOptionsByQuestionID(ctx, question.ID)
+				if err != nil {
+					return err
+				}
+				contentMap = make(map[string]int)
+				for _, option := range options {
+					contentMap[option.Content] = option.SerialNum
+				}
+				contentMaps[question.ID] = contentMap
+				deltas[question.ID] = make(map[int]int)
+			}
+			for _, content := range strings.Split(answer.Content, "┋") {
+				deltas[question.ID][contentMap[content]] += delta
+			}
+		}
+		return nil
+	}
+	if err := apply(oldAnswers, -1); err != nil {
+		return update, err
+	}
+	if err := apply(newAnswers, 1); err != nil {
+		return update, err
+	}
+	for qid, optionDeltas := range deltas {
+		questionDelta := LiveQuestionDelta{SerialNum: currentMap[qid].SerialNum, Options: make([]LiveOptionDelta, 0)}
+		for serial, delta := range optionDeltas {
+			if delta != 0 {
+				questionDelta.Options = append(questionDelta.Options, LiveOptionDelta{SerialNum: serial, Delta: delta})
+			}
+		}
+		if len(questionDelta.Options) == 0 {
+			continue
+		}
+		sort.Slice(questionDelta.Options, func(i, j int) bool {
+			return questionDelta.Options[i].SerialNum < questionDelta.Options[j].SerialNum
+		})
+		update.Questions = append(update.Questions, questionDelta)
+	}
+	sort.Slice(update.Questions, func(i, j int) bool {
+		return update.Questions[i].SerialNum < update.Questions[j].SerialNum
+	})
+	return update, nil
+}
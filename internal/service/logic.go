@@ -0,0 +1,153 @@
+package service
+
+import (
+	"sort"
+	"strings"
+
+	"QA-System/internal/dao"
+	"QA-System/internal/model"
+)
+
+// GetRulesBySurveyID 根据问卷ID获取显示逻辑
+func GetRulesBySurveyID(sid int64) ([]model.Rule, error) {
+	return d.GetRulesBySurveyID(ctx, sid)
+}
+
+// GetVisibleQuestions 根据已填写的答案计算每道题目是否可见
+// answers 为问题ID到答案内容的映射，返回问题ID到是否可见的映射
+func GetVisibleQuestions(questions []model.Question, rules []model.Rule,
+	answers map[int]string) (map[int]bool, error) {
+	sorted := make([]model.Question, len(questions))
+	copy(sorted, questions)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].SerialNum < sorted[j].SerialNum
+	})
+
+	serialMap := make(map[int]model.Question)
+	visible := make(map[int]bool)
+	for _, q := range sorted {
+		serialMap[q.SerialNum] = q
+		visible[q.ID] = true
+	}
+	// 存在显示规则的题目默认隐藏，满足条件后才显示
+	for _, rule := range rules {
+		if rule.Action != 1 {
+			continue
+		}
+		for _, target := range ruleTargets(sorted, serialMap, rule) {
+			visible[target.ID] = false
+		}
+	}
+
+	// 按题目顺序依次计算，规则只能由前面的题目影响后面的题目
+	for _, q := range sorted {
+		if !visible[q.ID] {
+			continue
+		}
+		for _, rule := range rules {
+			if rule.SerialNum != q.SerialNum {
+				continue
+			}
+			matched, err := matchRule(q, rule, answers[q.ID])
+			if err != nil {
+				return nil, err
+			}
+			if !matched {
+				continue
+			}
+			switch rule.Action {
+			case 1, 2:
+				for _, target := range ruleTargets(sorted, serialMap, rule) {
+					visible[target.ID] = rule.Action == 1
+				}
+			case 3:
+				targets := ruleTargets(sorted, serialMap, rule)
+				if len(targets) == 0 {
+					continue
+				}
+				for _, skipped := range sorted {
+					if skipped.SerialNum > q.SerialNum && skipped.SerialNum < targets[0].SerialNum {
+						visible[skipped.ID] = false
+					}
+				}
+			}
+		}
+	}
+	return visible, nil
+}
+
+// ruleTargets 获取显示逻辑作用的题目，目标为分页时返回分页内的全部题目
+func ruleTargets(sorted []model.Question, serialMap map[int]model.Question, rule model.Rule) []model.Question {
+	if rule.TargetSection == 0 {
+		if target, ok := serialMap[rule.TargetSerial]; ok {
+			return []model.Question{target}
+		}
+		return nil
+	}
+	targets := make([]model.Question, 0)
+	for _, q := range sorted {
+		if q.SectionNum == rule.TargetSection {
+			targets = append(targets, q)
+		}
+	}
+	return targets
+}
+
+// matchRule 判断答案是否满足显示逻辑的条件
+func matchRule(question model.Question, rule model.Rule, answer string) (bool, error) {
+	if answer == "" {
+		return false, nil
+	}
+	switch rule.MatchType {
+	case 1:
+		option, err := d.GetOptionByQIDAndSerialNum(ctx, question.ID, rule.OptionSerial)
+		if err != nil {
+			return false, err
+		}
+		for _, a := range strings.Split(answer, "┋") {
+			if a == option.Content {
+				return true, nil
+			}
+		}
+		return false, nil
+	case 2:
+		return strings.Contains(answer, rule.Text), nil
+	}
+	return false, nil
+}
+
+// GetLogicResponse 构建显示逻辑响应
+func GetLogicResponse(rules []model.Rule) []map[string]any {
+	logicResponse := make([]map[string]any, 0, len(rules))
+	for _, rule := range rules {
+		logicResponse = append(logicResponse, map[string]any{
+			"serial_num":     rule.SerialNum,
+			"match_type":     rule.MatchType,
+			"option_serial":  rule.OptionSerial,
+			"text":           rule.Text,
+			"action":         rule.Action,
+			"target_serial":  rule.TargetSerial,
+			"target_section": rule.TargetSection,
+		})
+	}
+	return logicResponse
+}
+
+func createRules(db dao.Daos, logic []dao.Rule, sid int64) error {
+	for _, rule := range logic {
+		var r model.Rule
+		r.SurveyID = sid
+		r.SerialNum = rule.SerialNum
+		r.MatchType = rule.MatchType
+		r.OptionSerial = rule.OptionSerial
+		r.Text = rule.Text
+		r.Action = rule.Action
+		r.TargetSerial = rule.TargetSerial
+		r.TargetSection = rule.TargetSection
+		err := db.CreateRule(ctx, r)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
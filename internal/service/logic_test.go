@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"QA-System/internal/model"
+
+	"gorm.io/gorm"
+)
+
+func (o *optionDaos) GetOptionByQIDAndSerialNum(_ context.Context, qid int, serialNum int) (*model.Option, error) {
+	for _, option := range o.options[qid] {
+		if option.SerialNum == serialNum {
+			return &option, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func TestGetVisibleQuestions(t *testing.T) {
+	// 题目1为选择题，题目3、4属于第2页，题目5属于第3页
+	questions := []model.Question{
+		{ID: 5, SerialNum: 5, SectionNum: 3},
+		{ID: 1, SerialNum: 1, SectionNum: 1, QuestionType: 2},
+		{ID: 2, SerialNum: 2, SectionNum: 1, QuestionType: 3},
+		{ID: 3, SerialNum: 3, SectionNum: 2},
+		{ID: 4, SerialNum: 4, SectionNum: 2},
+	}
+	original := d
+	d = &optionDaos{options: map[int][]model.Option{
+		1: {{SerialNum: 1, Content: "是"}, {SerialNum: 2, Content: "否"}},
+	}}
+	defer func() { d = original }()
+
+	showQ2 := model.Rule{SerialNum: 1, MatchType: 1, OptionSerial: 1, Action: 1, TargetSerial: 2}
+	hideQ2 := model.Rule{SerialNum: 1, MatchType: 1, OptionSerial: 2, Action: 2, TargetSerial: 2}
+	textShowQ3 := model.Rule{SerialNum: 2, MatchType: 2, Text: "急", Action: 1, TargetSerial: 3}
+	skipToQ5 := model.Rule{SerialNum: 1, MatchType: 1, OptionSerial: 2, Action: 3, TargetSerial: 5}
+	showSection2 := model.Rule{SerialNum: 1, MatchType: 1, OptionSerial: 1, Action: 1, TargetSection: 2}
+	skipToSection3 := model.Rule{SerialNum: 1, MatchType: 1, OptionSerial: 2, Action: 3, TargetSection: 3}
+	visible := func(hidden ...int) map[int]bool {
+		result := map[int]bool{1: true, 2: true, 3: true, 4: true, 5: true}
+		for _, id := range hidden {
+			result[id] = false
+		}
+		return result
+	}
+	tests := []struct {
+		name    string
+		rules   []model.Rule
+		answers map[int]string
+		want    map[int]bool
+	}{
+		{"没有规则", nil, map[int]string{}, visible()},
+		{"选中选项后显示", []model.Rule{showQ2}, map[int]string{1: "是"}, visible()},
+		{"多选中包含选项后显示", []model.Rule{showQ2}, map[int]string{1: "否┋是"}, visible()},
+		{"未选中选项时隐藏", []model.Rule{showQ2}, map[int]string{1: "否"}, visible(2)},
+		{"未作答时隐藏", []model.Rule{showQ2}, map[int]string{}, visible(2)},
+		{"选中选项后隐藏", []model.Rule{hideQ2}, map[int]string{1: "否"}, visible(2)},
+		{"未选中隐藏选项时显示", []model.Rule{hideQ2}, map[int]string{1: "是"}, visible()},
+		{"文本包含时显示", []model.Rule{textShowQ3}, map[int]string{2: "很急"}, visible()},
+		{"文本不包含时隐藏", []model.Rule{textShowQ3}, map[int]string{2: "不"}, visible(3)},
+		{"隐藏题目的规则不生效", []model.Rule{showQ2, textShowQ3}, map[int]string{1: "否", 2: "很急"}, visible(2, 3)},
+		{"跳转到题目", []model.Rule{skipToQ5}, map[int]string{1: "否"}, visible(2, 3, 4)},
+		{"未满足跳转条件", []model.Rule{skipToQ5}, map[int]string{1: "是"}, visible()},
+		{"显示分页", []model.Rule{showSection2}, map[int]string{1: "是"}, visible()},
+		{"未满足条件时隐藏分页", []model.Rule{showSection2}, map[int]string{1: "否"}, visible(3, 4)},
+		{"跳转到分页", []model.Rule{skipToSection3}, map[int]string{1: "否"}, visible(2, 3, 4)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetVisibleQuestions(questions, tt.rules, tt.answers)
+			if err != nil {
+				t.Fatalf("GetVisibleQuestions() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetVisibleQuestions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
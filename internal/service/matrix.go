@@ -0,0 +1,137 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"QA-System/internal/dao"
+	"QA-System/internal/model"
+)
+
+// GetMatrixRowCount 矩阵题单行统计
+type GetMatrixRowCount struct {
+	SerialNum int              `json:"serial_num"` // 行序号
+	Content   string           `json:"content"`    // 行内容
+	Total     int              `json:"total"`      // 该行作答人数
+	Options   []GetOptionCount `json:"options"`    // 各列统计
+}
+
+// SplitMatrixOptions 将矩阵题的选项拆分为行和列
+func SplitMatrixOptions(options []model.Option) ([]model.Option, []model.Option) {
+	rows := make([]model.Option, 0, len(options))
+	columns := make([]model.Option, 0)
+	for _, option := range options {
+		if option.IsColumn {
+			columns = append(columns, option)
+		} else {
+			rows = append(rows, option)
+		}
+	}
+	return rows, columns
+}
+
+// CheckMatrixAnswer 检查矩阵题答案是否符合要求
+func CheckMatrixAnswer(question *model.Question, answer string) error {
+	matrixAnswers, err := dao.ParseMatrixAnswer(answer)
+	if err != nil {
+		return err
+	}
+	options, err := d.GetOptionsByQuestionID(ctx, question.ID)
+	if err != nil {
+		return err
+	}
+	rows, columns := SplitMatrixOptions(options)
+	rowMap := make(map[int]bool)
+	for _, row := range rows {
+		rowMap[row.SerialNum] = true
+	}
+	columnMap := make(map[int]bool)
+	for _, column := range columns {
+		columnMap[column.SerialNum] = true
+	}
+	answered := make(map[int]bool)
+	for _, a := range matrixAnswers {
+		if !rowMap[a.Row] {
+			return errors.New("问题" + strconv.Itoa(question.SerialNum) + "不存在第" + strconv.Itoa(a.Row) + "行")
+		}
+		if !columnMap[a.Column] {
+			return errors.New("问题" + strconv.Itoa(question.SerialNum) + "不存在第" + strconv.Itoa(a.Column) + "列")
+		}
+		if answered[a.Row] {
+			return errors.New("问题" + strconv.Itoa(question.SerialNum) + "第" + strconv.Itoa(a.Row) + "行重复作答")
+		}
+		answered[a.Row] = true
+	}
+	if question.Required && len(answered) != len(rows) {
+		return errors.New("问题" + strconv.Itoa(question.SerialNum) + "存在未作答的行")
+	}
+	return nil
+}
+
+// formatMatrixAnswer 将矩阵题答案转换为可读的 "行内容:列内容" 形式
+func formatMatrixAnswer(questionID int, content string) string {
+	matrixAnswers, err := dao.ParseMatrixAnswer(content)
+	if err != nil {
+		return content
+	}
+	options, err := d.GetOptionsByQuestionID(ctx, questionID)
+	if err != nil {
+		return content
+	}
+	rows, columns := SplitMatrixOptions(options)
+	rowMap := make(map[int]string)
+	for _, row := range rows {
+		rowMap[row.SerialNum] = row.Content
+	}
+	columnMap := make(map[int]string)
+	for _, column := range columns {
+		columnMap[column.SerialNum] = column.Content
+	}
+	items := make([]string, 0, len(matrixAnswers))
+	for _, a := range matrixAnswers {
+		items = append(items, rowMap[a.Row]+":"+columnMap[a.Column])
+	}
+	return strings.Join(items, "┋")
+}
+
+// buildMatrixStats 根据各行各列的作答数量生成矩阵题按行统计结果
+func buildMatrixStats(question model.Question, options []model.Option,
+	counts map[int]map[int]int) GetChooseStatisticsResponse {
+	rows, columns := SplitMatrixOptions(options)
+	rowCounts := make([]GetMatrixRowCount, 0, len(rows))
+	for _, row := range rows {
+		total := 0
+		for _, count := range counts[row.SerialNum] {
+			total += count
+		}
+		columnCounts := make([]GetOptionCount, 0, len(columns))
+		for _, column := range columns {
+			count := counts[row.SerialNum][column.SerialNum]
+			percent := "0.00%"
+			if total > 0 {
+				percent = fmt.Sprintf("%.2f%%", float64(count)*100/float64(total))
+			}
+			columnCounts = append(columnCounts, GetOptionCount{
+				SerialNum: column.SerialNum,
+				Content:   column.Content,
+				Count:     count,
+				Percent:   percent,
+			})
+		}
+		rowCounts = append(rowCounts, GetMatrixRowCount{
+			SerialNum: row.SerialNum,
+			Content:   row.Content,
+			Total:     total,
+			Options:   columnCounts,
+		})
+	}
+	return GetChooseStatisticsResponse{
+		SerialNum:    question.SerialNum,
+		Question:     question.Subject,
+		QuestionType: question.QuestionType,
+		Options:      make([]GetOptionCount, 0),
+		Rows:         rowCounts,
+	}
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"QA-System/internal/model"
+
+	"github.com/zjutjh/WeJH-SDK/oauth"
+)
+
+// placeholderPattern 占位符语法，{{Q3}} 引用序号为3的题目的答案，{{user.name}} 引用答题者身份信息
+var placeholderPattern = regexp.MustCompile(`\{\{\s*(Q\d+|user\.[a-z_]+)\s*\}\}`)
+
+// AnswerPlaceholder 引用答案的占位符，由前端根据答题者当前的作答实时替换
+type AnswerPlaceholder struct {
+	Placeholder string `json:"placeholder"` // 文本中的占位符，如 {{Q3}}
+	QuestionID  int    `json:"question_id"` // 引用的题目ID
+	SerialNum   int    `json:"serial_num"`  // 引用的题目序号
+}
+
+// PlaceholderResolver 占位符解析器，身份信息占位符在服务端替换，答案占位符保留并返回说明
+type PlaceholderResolver struct {
+	identity  map[string]string
+	questions map[int]int
+}
+
+// NewPlaceholderResolver 创建占位符解析器，userInfo 为空时身份信息占位符替换为空字符串
+func NewPlaceholderResolver(userInfo *oauth.UserInfo, questions []model.Question) *PlaceholderResolver {
+	resolver := &PlaceholderResolver{
+		identity:  make(map[string]string),
+		questions: make(map[int]int),
+	}
+	if userInfo != nil {
+		// 字段名与答题记录一致
+		resolver.identity = map[string]string{
+			"user.name":           userInfo.Name,
+			"user.college":        userInfo.College,
+			"user.student_id":     userInfo.StudentID,
+			"user.gender":         userInfo.Gender,
+			"user.user_type":      userInfo.UserType,
+			"user.user_type_desc": userInfo.UserTypeDesc,
+		}
+	}
+	for _, question := range questions {
+		resolver.questions[question.SerialNum] = question.ID
+	}
+	return resolver
+}
+
+// Resolve 替换文本中的身份信息占位符，返回替换后的文本和文本中引用答案的占位符
+// 引用不存在的题目或未知身份字段的占位符替换为空字符串
+func (r *PlaceholderResolver) Resolve(text string) (string, []AnswerPlaceholder) {
+	placeholders := make([]AnswerPlaceholder, 0)
+	if !strings.Contains(text, "{{") {
+		return text, placeholders
+	}
+	seen := make(map[int]bool)
+	text = placeholderPattern.ReplaceAllStringFunc(text, func(match string) string {
+		name := placeholderPattern.FindStringSubmatch(match)[1]
+		if !strings.HasPrefix(name, "Q") {
+			return r.identity[name]
+		}
+		serial, err := strconv.Atoi(name[1:])
+		if err != nil {
+			return ""
+		}
+		id, ok := r.questions[serial]
+		if !ok {
+			return ""
+		}
+		placeholder := "{{" + name + "}}"
+		if !seen[serial] {
+			seen[serial] = true
+			placeholders = append(placeholders, AnswerPlaceholder{
+				Placeholder: placeholder,
+				QuestionID:  id,
+				SerialNum:   serial,
+			})
+		}
+		return placeholder
+	})
+	return text, placeholders
+}
+
+// ResolveAll 依次替换多段文本中的身份信息占位符，返回各段文本引用答案的占位符的合集
+func (r *PlaceholderResolver) ResolveAll(texts ...*string) []AnswerPlaceholder {
+	placeholders := make([]AnswerPlaceholder, 0)
+	seen := make(map[int]bool)
+	for _, text := range texts {
+		var found []AnswerPlaceholder
+		*text, found = r.Resolve(*text)
+		for _, placeholder := range found {
+			if !seen[placeholder.SerialNum] {
+				seen[placeholder.SerialNum] = true
+				placeholders = append(placeholders, placeholder)
+			}
+		}
+	}
+	return placeholders
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"QA-System/internal/model"
+
+	"github.com/zjutjh/WeJH-SDK/oauth"
+)
+
+func TestPlaceholderResolverResolve(t *testing.T) {
+	userInfo := &oauth.UserInfo{
+		Name:         "张三",
+		College:      "计算机学院",
+		StudentID:    "202412345678",
+		Gender:       "男",
+		UserType:     "1",
+		UserTypeDesc: "本科生",
+	}
+	questions := []model.Question{{ID: 11, SerialNum: 1}, {ID: 13, SerialNum: 3}}
+	resolver := NewPlaceholderResolver(userInfo, questions)
+	tests := []struct {
+		name      string
+		resolver  *PlaceholderResolver
+		text      string
+		want      string
+		wantFound []AnswerPlaceholder
+	}{
+		{"没有占位符", resolver, "你好", "你好", []AnswerPlaceholder{}},
+		{"替换身份信息", resolver, "{{user.name}}（{{ user.college }}）", "张三（计算机学院）", []AnswerPlaceholder{}},
+		{"用户类型与答题记录字段一致", resolver, "{{user.user_type}}:{{user.user_type_desc}}", "1:本科生",
+			[]AnswerPlaceholder{}},
+		{"未登录时身份信息为空", NewPlaceholderResolver(nil, questions), "你好{{user.name}}", "你好",
+			[]AnswerPlaceholder{}},
+		{"未知身份字段为空", resolver, "{{user.phone}}", "", []AnswerPlaceholder{}},
+		{"保留答案占位符", resolver, "你选择了{{ Q3 }}", "你选择了{{Q3}}",
+			[]AnswerPlaceholder{{Placeholder: "{{Q3}}", QuestionID: 13, SerialNum: 3}}},
+		{"不存在的题目为空", resolver, "{{Q2}}{{Q1}}", "{{Q1}}",
+			[]AnswerPlaceholder{{Placeholder: "{{Q1}}", QuestionID: 11, SerialNum: 1}}},
+		{"重复引用只返回一次", resolver, "{{Q3}}和{{Q1}}和{{Q3}}", "{{Q3}}和{{Q1}}和{{Q3}}",
+			[]AnswerPlaceholder{
+				{Placeholder: "{{Q3}}", QuestionID: 13, SerialNum: 3},
+				{Placeholder: "{{Q1}}", QuestionID: 11, SerialNum: 1},
+			}},
+		{"不符合语法的文本不变", resolver, "{{q3}}{{Q}}", "{{q3}}{{Q}}", []AnswerPlaceholder{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := tt.resolver.Resolve(tt.text)
+			if got != tt.want {
+				t.Errorf("Resolve(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+			if !reflect.DeepEqual(found, tt.wantFound) {
+				t.Errorf("Resolve(%q) placeholders = %+v, want %+v", tt.text, found, tt.wantFound)
+			}
+		})
+	}
+}
+
+func TestPlaceholderResolverResolveAll(t *testing.T) {
+	resolver := NewPlaceholderResolver(nil, []model.Question{{ID: 11, SerialNum: 1}, {ID: 13, SerialNum: 3}})
+	subject, description := "{{Q1}}", "{{Q3}}{{Q1}}"
+	found := resolver.ResolveAll(&subject, &description)
+	want := []AnswerPlaceholder{
+		{Placeholder: "{{Q1}}", QuestionID: 11, SerialNum: 1},
+		{Placeholder: "{{Q3}}", QuestionID: 13, SerialNum: 3},
+	}
+	if !reflect.DeepEqual(found, want) {
+		t.Errorf("ResolveAll() = %+v, want %+v", found, want)
+	}
+}
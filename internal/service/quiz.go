@@ -0,0 +1,159 @@
+package service
+
+import (
+	"math"
+	"slices"
+	"strings"
+
+	"QA-System/internal/dao"
+	"QA-System/internal/model"
+)
+
+// GetScoreStatistics 测验得分统计
+type GetScoreStatistics struct {
+	Count        int              `json:"count"`        // 答卷数量
+	FullScore    float64          `json:"full_score"`   // 满分
+	Mean         float64          `json:"mean"`         // 平均分
+	Median       float64          `json:"median"`       // 中位数
+	Max          float64          `json:"max"`          // 最高分
+	Min          float64          `json:"min"`          // 最低分
+	Distribution []GetOptionCount `json:"distribution"` // 各分数的人数分布
+}
+
+// GetQuestionFullScore 计算题目满分，按选项计分时为可获得的最高分
+func GetQuestionFullScore(question model.Question, options []model.Option) float64 {
+	optionScore := 0.0
+	maxOptionScore := 0.0
+	for _, option := range options {
+		if option.IsColumn || option.Score <= 0 {
+			continue
+		}
+		optionScore += option.Score
+		maxOptionScore = math.Max(maxOptionScore, option.Score)
+	}
+	switch {
+	case optionScore == 0:
+		return question.Score
+	case question.QuestionType == 1:
+		return maxOptionScore
+	default:
+		return optionScore
+	}
+}
+
+// GradeAnswer 计算单道题目的得分
+// 选项设置了分值时按所选选项累加计分，错误选项可设为负分，总分不低于0，否则全部答对得到题目分值
+func GradeAnswer(question model.Question, options []model.Option, answer string) float64 {
+	if answer == "" {
+		return 0
+	}
+	switch question.QuestionType {
+	case 1, 2:
+		selected := make(map[string]bool)
+		for _, a := range strings.Split(answer, "┋") {
+			selected[a] = true
+		}
+		useOptionScore := false
+		optionScore := 0.0
+		allCorrect := true
+		for _, option := range options {
+			if option.IsColumn {
+				continue
+			}
+			if option.Score != 0 {
+				useOptionScore = true
+			}
+			if selected[option.Content] {
+				optionScore += option.Score
+			}
+			if selected[option.Content] != option.IsCorrect {
+				allCorrect = false
+			}
+		}
+		if useOptionScore {
+			return math.Max(optionScore, 0)
+		}
+		if allCorrect {
+			return question.Score
+		}
+	case 3:
+		for _, key := range strings.Split(question.AnswerKey, "┋") {
+			if key != "" && strings.EqualFold(strings.TrimSpace(key), strings.TrimSpace(answer)) {
+				return question.Score
+			}
+		}
+	}
+	return 0
+}
+
+// gradeAnswerSheet 为测验答卷中的每道题目评分并计算总分
+func gradeAnswerSheet(answerSheet *dao.AnswerSheet) error {
+	total := 0.0
+	for i, answer := range answerSheet.Answers {
+		question, err := d.GetQuestionByID(ctx, answer.QuestionID)
+		if err != nil {
+			return err
+		}
+		options, err := d.GetOptionsByQuestionID(ctx, question.ID)
+		if err != nil {
+			return err
+		}
+		score := GradeAnswer(*question, options, answer.Content)
+		answerSheet.Answers[i].Score = score
+		total += score
+	}
+	answerSheet.Score = total
+	return nil
+}
+
+// GetSurveyFullScore 计算测验满分
+func GetSurveyFullScore(questions []model.Question) (float64, error) {
+	total := 0.0
+	for _, question := range questions {
+		options, err := d.GetOptionsByQuestionID(ctx, question.ID)
+		if err != nil {
+			return 0, err
+		}
+		total += GetQuestionFullScore(question, options)
+	}
+	return total, nil
+}
+
+// GetSurveyScoreStats 获取测验的得分统计
+func GetSurveyScoreStats(sid int64) (*GetScoreStatistics, error) {
+	answerSheets, err := GetSurveyAnswersBySurveyID(sid)
+	if err != nil {
+		return nil, err
+	}
+	questions, err := d.GetQuestionsBySurveyID(ctx, sid)
+	if err != nil {
+		return nil, err
+	}
+	return GenerateScoreStats(questions, answerSheets)
+}
+
+// GenerateScoreStats 生成测验得分统计
+func GenerateScoreStats(questions []model.Question, answerSheets []dao.AnswerSheet) (*GetScoreStatistics, error) {
+	fullScore, err := GetSurveyFullScore(questions)
+	if err != nil {
+		return nil, err
+	}
+	scores := make([]float64, 0, len(answerSheets))
+	for _, sheet := range answerSheets {
+		scores = append(scores, sheet.Score)
+	}
+	// 得分分布与滑块量表的统计方式一致，只列出出现过的分数
+	stats := buildScaleStats(model.Question{ScaleType: 3}, scores)
+	result := &GetScoreStatistics{
+		Count:        stats.Scale.Count,
+		FullScore:    fullScore,
+		Mean:         stats.Scale.Mean,
+		Median:       stats.Scale.Median,
+		Distribution: stats.Options,
+	}
+	if len(scores) > 0 {
+		result.Min = slices.Min(scores)
+		result.Max = slices.Max(scores)
+	}
+	return result, nil
+}
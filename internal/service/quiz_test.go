@@ -0,0 +1,79 @@
+package service
+
+import (
+	"testing"
+
+	"QA-System/internal/model"
+)
+
+func TestGradeAnswer(t *testing.T) {
+	single := model.Question{QuestionType: 1, Score: 2}
+	multiple := model.Question{QuestionType: 2, Score: 4}
+	options := []model.Option{
+		{Content: "A", IsCorrect: true},
+		{Content: "B", IsCorrect: true},
+		{Content: "C"},
+	}
+	scored := []model.Option{
+		{Content: "A", IsCorrect: true, Score: 2},
+		{Content: "B", IsCorrect: true, Score: 2},
+		{Content: "C", Score: -3},
+		{Content: "列", IsColumn: true, Score: 5},
+	}
+	singleOptions := []model.Option{{Content: "A", IsCorrect: true}, {Content: "B"}}
+	fill := model.Question{QuestionType: 3, Score: 3, AnswerKey: "杭州┋Hangzhou"}
+	tests := []struct {
+		name     string
+		question model.Question
+		options  []model.Option
+		answer   string
+		want     float64
+	}{
+		{"未作答", single, singleOptions, "", 0},
+		{"单选正确", single, singleOptions, "A", 2},
+		{"单选错误", single, singleOptions, "B", 0},
+		{"多选全部正确", multiple, options, "A┋B", 4},
+		{"多选漏选", multiple, options, "A", 0},
+		{"多选多选错误选项", multiple, options, "A┋B┋C", 0},
+		{"按选项计分", multiple, scored, "A", 2},
+		{"按选项累加", multiple, scored, "A┋B", 4},
+		{"全选时错误选项扣分", multiple, scored, "A┋B┋C", 1},
+		{"扣分后不低于0", multiple, scored, "A┋C", 0},
+		{"其他选项不计分", multiple, scored, "A┋自定义", 2},
+		{"只选其他选项", single, singleOptions, "自定义", 0},
+		{"填空题匹配标准答案", fill, nil, "杭州", 3},
+		{"填空题忽略大小写和首尾空白", fill, nil, " hangzhou ", 3},
+		{"填空题不匹配", fill, nil, "宁波", 0},
+		{"填空题没有标准答案", model.Question{QuestionType: 3, Score: 3}, nil, "杭州", 0},
+		{"不支持评分的题型", model.Question{QuestionType: 4, Score: 3}, nil, "杭州", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GradeAnswer(tt.question, tt.options, tt.answer); got != tt.want {
+				t.Errorf("GradeAnswer(%q) = %v, want %v", tt.answer, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetQuestionFullScore(t *testing.T) {
+	tests := []struct {
+		name     string
+		question model.Question
+		options  []model.Option
+		want     float64
+	}{
+		{"不按选项计分", model.Question{QuestionType: 2, Score: 4}, []model.Option{{Content: "A"}}, 4},
+		{"单选取最高选项分", model.Question{QuestionType: 1, Score: 4},
+			[]model.Option{{Score: 1}, {Score: 3}, {Score: -1}}, 3},
+		{"多选累加正分选项", model.Question{QuestionType: 2},
+			[]model.Option{{Score: 2}, {Score: 2}, {Score: -3}, {Score: 5, IsColumn: true}}, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GetQuestionFullScore(tt.question, tt.options); got != tt.want {
+				t.Errorf("GetQuestionFullScore() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"QA-System/internal/dao"
+	"QA-System/internal/model"
+	"QA-System/internal/pkg/redis"
+
+	redisPkg "github.com/redis/go-redis/v9"
+	"github.com/zjutjh/WeJH-SDK/oauth"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ErrQuotaFull 答卷配额已满
+var ErrQuotaFull = errors.New("答卷配额已满")
+
+// QuotaFields 支持设置配额的答题者属性
+var QuotaFields = map[string]bool{
+	"college":        true,
+	"gender":         true,
+	"user_type":      true,
+	"user_type_desc": true,
+}
+
+// GetQuotaProgress 配额进度
+type GetQuotaProgress struct {
+	Field string `json:"field"` // 配额字段
+	Value string `json:"value"` // 配额字段取值
+	Limit int    `json:"limit"` // 配额上限
+	Count int64  `json:"count"` // 已回收答卷数量
+}
+
+// GetQuotasBySurveyID 根据问卷ID获取配额
+func GetQuotasBySurveyID(sid int64) ([]model.Quota, error) {
+	return d.GetQuotasBySurveyID(ctx, sid)
+}
+
+// quotaKey 配额计数的键
+func quotaKey(sid int64, field string, value string) string {
+	return fmt.Sprintf("quota:sid:%d:field:%s:value:%s", sid, field, value)
+}
+
+// getUserField 获取答题者在配额字段上的取值
+func getUserField(userInfo oauth.UserInfo, field string) string {
+	switch field {
+	case "college":
+		return userInfo.College
+	case "gender":
+		return userInfo.Gender
+	case "user_type":
+		return userInfo.UserType
+	case "user_type_desc":
+		return userInfo.UserTypeDesc
+	}
+	return ""
+}
+
+// getRecordField 获取答题记录在配额字段上的取值，与 getUserField 对应
+// 交叉分析的答题者信息字段是配额字段的子集，也使用该函数取值
+func getRecordField(record dao.RecordSheet, field string) string {
+	switch field {
+	case "college":
+		return record.College
+	case "gender":
+		return record.Gender
+	case "user_type":
+		return record.UserType
+	case "user_type_desc":
+		return record.UserTypeDesc
+	}
+	return ""
+}
+
+// ReserveQuota 为答题者占用其所属群体的配额
+// 返回已占用配额的键，配额已满时返回 ErrQuotaFull 以及对应的配额
+func ReserveQuota(sid int64, userInfo oauth.UserInfo) ([]string, *model.Quota, error) {
+	quotas, err := d.GetQuotasBySurveyID(ctx, sid)
+	if err != nil {
+		return nil, nil, err
+	}
+	keys := make([]string, 0)
+	limits := make([]any, 0)
+	matched := make([]model.Quota, 0)
+	for _, quota := range quotas {
+		if getUserField(userInfo, quota.Field) != quota.Value {
+			continue
+		}
+		key := quotaKey(sid, quota.Field, quota.Value)
+		// 计数不存在时以已保存的记录数初始化
+		exists, err := redis.RedisClient.Exists(ctx, key).Result()
+		if err != nil {
+			return nil, nil, err
+		}
+		if exists == 0 {
+			count, err := d.CountRecordSheets(ctx, sid, quota.Field, quota.Value)
+			if err != nil {
+				return nil, nil, err
+			}
+			if err := redis.RedisClient.SetNX(ctx, key, count, 0).Err(); err != nil {
+				return nil, nil, err
+			}
+		}
+		keys = append(keys, key)
+		limits = append(limits, quota.Limit)
+		matched = append(matched, quota)
+	}
+	if len(keys) == 0 {
+		return keys, nil, nil
+	}
+	index, err := reserveScript.Run(ctx, redis.RedisClient, keys, limits...).Int()
+	if err != nil {
+		return nil, nil, err
+	}
+	if index != 0 {
+		return nil, &matched[index-1], ErrQuotaFull
+	}
+	return keys, nil, nil
+}
+
+// ReleaseQuota 释放已占用的配额
+func ReleaseQuota(keys []string) error {
+	return releaseCounters(keys)
+}
+
+// releaseQuotaScript 计数存在时减一，计数不存在时保持不存在，下次占用时以记录数初始化
+var releaseQuotaScript = redisPkg.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 1 then
+	return redis.call("DECR", KEYS[1])
+end
+return 0
+`)
+
+// releaseRecordQuota 删除答卷对应的记录并释放其占用的配额，使配额计数与记录数保持一致
+func releaseRecordQuota(sid int64, answerID primitive.ObjectID) error {
+	record, err := d.GetRecordSheetByAnswerID(ctx, sid, answerID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := d.DeleteRecordSheetByAnswerID(ctx, sid, answerID); err != nil {
+		return err
+	}
+	quotas, err := d.GetQuotasBySurveyID(ctx, sid)
+	if err != nil {
+		return err
+	}
+	for _, quota := range quotas {
+		if getRecordField(*record, quota.Field) != quota.Value {
+			continue
+		}
+		err := releaseQuotaScript.Run(ctx, redis.RedisClient, []string{quotaKey(sid, quota.Field, quota.Value)}).Err()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetQuotaProgressBySurveyID 获取问卷各配额的回收进度
+func GetQuotaProgressBySurveyID(sid int64) ([]GetQuotaProgress, error) {
+	quotas, err := d.GetQuotasBySurveyID(ctx, sid)
+	if err != nil {
+		return nil, err
+	}
+	progress := make([]GetQuotaProgress, 0, len(quotas))
+	for _, quota := range quotas {
+		count, err := d.CountRecordSheets(ctx, sid, quota.Field, quota.Value)
+		if err != nil {
+			return nil, err
+		}
+		progress = append(progress, GetQuotaProgress{
+			Field: quota.Field,
+			Value: quota.Value,
+			Limit: quota.Limit,
+			Count: count,
+		})
+	}
+	return progress, nil
+}
+
+// GetQuotaResponse 构建配额响应
+func GetQuotaResponse(quotas []model.Quota) []map[string]any {
+	quotaResponse := make([]map[string]any, 0, len(quotas))
+	for _, quota := range quotas {
+		quotaResponse = append(quotaResponse, map[string]any{
+			"field": quota.Field,
+			"value": quota.Value,
+			"limit": quota.Limit,
+		})
+	}
+	return quotaResponse
+}
+
+func createQuotas(db dao.Daos, quotas []dao.Quota, sid int64) error {
+	for _, quota := range quotas {
+		var q model.Quota
+		q.SurveyID = sid
+		q.Field = quota.Field
+		q.Value = quota.Value
+		q.Limit = quota.Limit
+		err := db.CreateQuota(ctx, q)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteQuotaCounters 删除问卷所有配额计数
+func deleteQuotaCounters(sid int64) error {
+	return deleteKeys(fmt.Sprintf("quota:sid:%d:*", sid))
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"QA-System/internal/model"
+)
+
+// GetRankingCount 排序题单个选项统计
+type GetRankingCount struct {
+	SerialNum   int     `json:"serial_num"`   // 选项序号
+	Content     string  `json:"content"`      // 选项内容
+	Borda       int     `json:"borda"`        // Borda 得分
+	AverageRank float64 `json:"average_rank"` // 平均排名
+	Rank        int     `json:"rank"`         // 按 Borda 得分的排名
+}
+
+// ParseRankingAnswer 解析排序题答案，答案为按顺序排列的选项序号，用 "┋" 分隔
+func ParseRankingAnswer(content string) ([]int, error) {
+	serials := make([]int, 0)
+	if content == "" {
+		return serials, nil
+	}
+	for _, item := range strings.Split(content, "┋") {
+		serial, err := strconv.Atoi(item)
+		if err != nil {
+			return nil, errors.New("排序题答案格式错误")
+		}
+		serials = append(serials, serial)
+	}
+	return serials, nil
+}
+
+// CheckRankingAnswer 检查排序题答案是否包含全部选项且不重复
+func CheckRankingAnswer(question *model.Question, answer string) error {
+	serials, err := ParseRankingAnswer(answer)
+	if err != nil {
+		return err
+	}
+	options, err := d.GetOptionsByQuestionID(ctx, question.ID)
+	if err != nil {
+		return err
+	}
+	optionMap := make(map[int]bool)
+	for _, option := range options {
+		optionMap[option.SerialNum] = true
+	}
+	ranked := make(map[int]bool)
+	for _, serial := range serials {
+		if !optionMap[serial] {
+			return errors.New("问题" + strconv.Itoa(question.SerialNum) + "不存在选项" + strconv.Itoa(serial))
+		}
+		if ranked[serial] {
+			return errors.New("问题" + strconv.Itoa(question.SerialNum) + "选项" + strconv.Itoa(serial) + "重复排序")
+		}
+		ranked[serial] = true
+	}
+	if len(ranked) != len(options) {
+		return errors.New("问题" + strconv.Itoa(question.SerialNum) + "存在未排序的选项")
+	}
+	return nil
+}
+
+// buildRankingStats 根据各选项在各名次的数量计算 Borda 得分和平均排名
+// 第 i 名(从 0 开始)得到 n-1-i 分，n 为选项数量
+func buildRankingStats(options []model.Option, positions map[int]map[int]int) []GetRankingCount {
+	n := len(options)
+	borda := make(map[int]int)
+	rankSum := make(map[int]int)
+	rankNum := make(map[int]int)
+	for serial, counts := range positions {
+		for i, count := range counts {
+			borda[serial] += (n - 1 - i) * count
+			rankSum[serial] += (i + 1) * count
+			rankNum[serial] += count
+		}
+	}
+
+	result := make([]GetRankingCount, 0, n)
+	for _, option := range options {
+		averageRank := 0.0
+		if rankNum[option.SerialNum] > 0 {
+			averageRank = float64(rankSum[option.SerialNum]) / float64(rankNum[option.SerialNum])
+			averageRank = math.Round(averageRank*100) / 100
+		}
+		result = append(result, GetRankingCount{
+			SerialNum:   option.SerialNum,
+			Content:     option.Content,
+			Borda:       borda[option.SerialNum],
+			AverageRank: averageRank,
+		})
+	}
+
+	// 按 Borda 得分降序计算排名，得分相同排名相同
+	sorted := make([]GetRankingCount, len(result))
+	copy(sorted, result)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Borda == sorted[j].Borda {
+			return sorted[i].SerialNum < sorted[j].SerialNum
+		}
+		return sorted[i].Borda > sorted[j].Borda
+	})
+	rankMap := make(map[int]int)
+	currentRank := 1
+	for i := range sorted {
+		if i > 0 && sorted[i].Borda < sorted[i-1].Borda {
+			currentRank = i + 1
+		}
+		rankMap[sorted[i].SerialNum] = currentRank
+	}
+	for i := range result {
+		result[i].Rank = rankMap[result[i].SerialNum]
+	}
+	return result
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"QA-System/internal/model"
+)
+
+func TestParseRankingAnswer(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []int
+		wantErr bool
+	}{
+		{"空答案", "", []int{}, false},
+		{"按顺序排列", "3┋1┋2", []int{3, 1, 2}, false},
+		{"非序号", "1┋a", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRankingAnswer(tt.content)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseRankingAnswer(%q) error = %v, wantErr %v", tt.content, err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseRankingAnswer(%q) = %v, want %v", tt.content, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildRankingStats(t *testing.T) {
+	options := []model.Option{
+		{SerialNum: 1, Content: "A"},
+		{SerialNum: 2, Content: "B"},
+		{SerialNum: 3, Content: "C"},
+	}
+	tests := []struct {
+		name      string
+		positions map[int]map[int]int
+		want      []GetRankingCount
+	}{
+		{
+			name:      "无作答",
+			positions: map[int]map[int]int{},
+			want: []GetRankingCount{
+				{SerialNum: 1, Content: "A", Rank: 1},
+				{SerialNum: 2, Content: "B", Rank: 1},
+				{SerialNum: 3, Content: "C", Rank: 1},
+			},
+		},
+		{
+			// 两份答卷：A>B>C 和 B>A>C
+			name: "得分相同排名相同",
+			positions: map[int]map[int]int{
+				1: {0: 1, 1: 1},
+				2: {0: 1, 1: 1},
+				3: {2: 2},
+			},
+			want: []GetRankingCount{
+				{SerialNum: 1, Content: "A", Borda: 3, AverageRank: 1.5, Rank: 1},
+				{SerialNum: 2, Content: "B", Borda: 3, AverageRank: 1.5, Rank: 1},
+				{SerialNum: 3, Content: "C", Borda: 0, AverageRank: 3, Rank: 3},
+			},
+		},
+		{
+			// 三份答卷：C>A>B、C>B>A、A>C>B
+			name: "按 Borda 得分排名",
+			positions: map[int]map[int]int{
+				1: {0: 1, 1: 1, 2: 1},
+				2: {1: 1, 2: 2},
+				3: {0: 2, 1: 1},
+			},
+			want: []GetRankingCount{
+				{SerialNum: 1, Content: "A", Borda: 3, AverageRank: 2, Rank: 2},
+				{SerialNum: 2, Content: "B", Borda: 1, AverageRank: 2.67, Rank: 3},
+				{SerialNum: 3, Content: "C", Borda: 5, AverageRank: 1.33, Rank: 1},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := buildRankingStats(options, tt.positions)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("buildRankingStats() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"QA-System/internal/model"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// SeriesInstance 周期问卷的一期
+type SeriesInstance struct {
+	ID        int64     `json:"id"`
+	Title     string    `json:"title"`
+	StartTime time.Time `json:"start_time"`
+	Deadline  time.Time `json:"deadline"`
+	Status    int       `json:"status"`
+	Num       int       `json:"num"`
+}
+
+// recurrenceLocation 周期问卷规则的时间按北京时间计算，与服务器时区无关
+var recurrenceLocation = time.FixedZone("CST", 8*60*60)
+
+// NextOccurrence 计算周期问卷规则在 after 之后的下一期开始时间
+func NextOccurrence(recurrence model.Recurrence, after time.Time) time.Time {
+	after = after.In(recurrenceLocation)
+	if recurrence.Frequency == 2 {
+		next := time.Date(after.Year(), after.Month(), recurrence.MonthDay, recurrence.Hour, recurrence.Minute, 0, 0,
+			recurrenceLocation)
+		if !next.After(after) {
+			next = next.AddDate(0, 1, 0)
+		}
+		return next
+	}
+	next := time.Date(after.Year(), after.Month(), after.Day(), recurrence.Hour, recurrence.Minute, 0, 0,
+		recurrenceLocation)
+	next = next.AddDate(0, 0, (recurrence.Weekday-int(next.Weekday())+7)%7)
+	if !next.After(after) {
+		next = next.AddDate(0, 0, 7)
+	}
+	return next
+}
+
+// SaveRecurrence 设置问卷的周期规则，从当前时间开始计算下一期
+func SaveRecurrence(recurrence model.Recurrence) (*model.Recurrence, error) {
+	old, err := d.GetRecurrenceBySurveyID(ctx, recurrence.SurveyID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if err == nil {
+		recurrence.ID = old.ID
+	}
+	recurrence.NextRunAt = NextOccurrence(recurrence, time.Now())
+	err = d.SaveRecurrence(ctx, &recurrence)
+	return &recurrence, err
+}
+
+// GetRecurrenceBySurveyID 根据问卷ID获取周期问卷规则
+func GetRecurrenceBySurveyID(sid int64) (*model.Recurrence, error) {
+	return d.GetRecurrenceBySurveyID(ctx, sid)
+}
+
+// DeleteRecurrenceBySurveyID 删除问卷的周期规则，已生成的问卷保留
+func DeleteRecurrenceBySurveyID(sid int64) error {
+	return d.DeleteRecurrenceBySurveyID(ctx, sid)
+}
+
+// spawnRecurringSurveys 为已到期的周期问卷规则生成新一期问卷
+// 停机期间错过且已结束的期数不再补发
+// 先以比较并更新的方式推进下一期开始时间，更新成功的实例才生成问卷，避免调度锁过期后同一期被重复生成
+func spawnRecurringSurveys(now time.Time) {
+	recurrences, err := d.GetDueRecurrences(ctx, now)
+	if err != nil {
+		zap.L().Error("获取周期问卷规则失败", zap.Error(err))
+		return
+	}
+	for _, recurrence := range recurrences {
+		start := recurrence.NextRunAt
+		ok, err := d.AdvanceRecurrence(ctx, recurrence.ID, start, NextOccurrence(recurrence, now))
+		if err != nil {
+			zap.L().Error("更新周期问卷规则失败", zap.Int64("survey_id", recurrence.SurveyID), zap.Error(err))
+			continue
+		}
+		if !ok || !start.Add(time.Duration(recurrence.Duration)*time.Hour).After(now) {
+			continue
+		}
+		if err := spawnSurvey(recurrence, start); err != nil {
+			zap.L().Error("生成周期问卷失败", zap.Int64("survey_id", recurrence.SurveyID), zap.Error(err))
+		}
+	}
+}
+
+// spawnSurvey 以周期规则的问卷为模板生成一期问卷并发布
+func spawnSurvey(recurrence model.Recurrence, start time.Time) error {
+	source, err := d.GetSurveyByID(ctx, recurrence.SurveyID)
+	if err != nil {
+		return err
+	}
+	if _, err := CheckSurveyComplete(source); err != nil {
+		return err
+	}
+	definition, err := GetSurveyDefinition(source)
+	if err != nil {
+		return err
+	}
+	deadline := start.Add(time.Duration(recurrence.Duration) * time.Hour)
+	definition.BaseConfig.StartTime = start.In(recurrenceLocation).Format(time.RFC3339)
+	definition.BaseConfig.EndTime = deadline.In(recurrenceLocation).Format(time.RFC3339)
+	definition.QuestionConfig.Title = fmt.Sprintf("%s（%s）", source.Title,
+		start.In(recurrenceLocation).Format("2006-01-02"))
+	sid, err := createSurveyWithImages(recurrence.UserID, definition)
+	if err != nil {
+		return err
+	}
+	err = d.UpdateSurveySeries(ctx, sid, recurrence.ID)
+	if err != nil {
+		return err
+	}
+	return UpdateSurveyStatus(sid, 2)
+}
+
+// GetSeriesInstances 获取周期问卷规则已生成的各期问卷
+func GetSeriesInstances(recurrence *model.Recurrence) ([]SeriesInstance, error) {
+	surveys, err := d.GetSurveysBySeriesID(ctx, recurrence.ID)
+	if err != nil {
+		return nil, err
+	}
+	instances := make([]SeriesInstance, 0, len(surveys))
+	for _, survey := range surveys {
+		instances = append(instances, SeriesInstance{
+			ID:        survey.ID,
+			Title:     survey.Title,
+			StartTime: survey.StartTime,
+			Deadline:  survey.Deadline,
+			Status:    survey.Status,
+			Num:       survey.Num,
+		})
+	}
+	return instances, nil
+}
+
+// GetSeriesStatistics 汇总各期问卷的选择题统计，按题目标识和选项序号合并，返回统计结果和答卷总数
+// 题目序号和内容以最近一期为准
+func GetSeriesStatistics(instances []SeriesInstance) ([]GetChooseStatisticsResponse, int, error) {
+	merged := make(map[string]*GetChooseStatisticsResponse)
+	counts := make(map[string]map[int]*GetOptionCount)
+	total := 0
+	for _, instance := range instances {
+		count, err := d.CountAnswerSheetsBySurveyID(ctx, instance.ID)
+		if err != nil {
+			return nil, 0, err
+		}
+		total += int(count)
+		questions, err := d.GetQuestionsBySurveyID(ctx, instance.ID)
+		if err != nil {
+			return nil, 0, err
+		}
+		keys := make(map[int]string, len(questions))
+		for _, question := range questions {
+			keys[question.SerialNum] = QuestionKey(question)
+		}
+		stats, err := GetSurveyStatistics(instance.ID)
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, stat := range stats {
+			if stat.QuestionType != 1 && stat.QuestionType != 2 {
+				continue
+			}
+			key := keys[stat.SerialNum]
+			if _, ok := merged[key]; !ok {
+				merged[key] = &GetChooseStatisticsResponse{QuestionType: stat.QuestionType}
+				counts[key] = make(map[int]*GetOptionCount)
+			}
+			merged[key].SerialNum = stat.SerialNum
+			merged[key].Question = stat.Question
+			for _, option := range stat.Options {
+				count, ok := counts[key][option.SerialNum]
+				if !ok {
+					count = &GetOptionCount{SerialNum: option.SerialNum}
+					counts[key][option.SerialNum] = count
+				}
+				count.Content = option.Content
+				count.Count += option.Count
+			}
+		}
+	}
+	response := make([]GetChooseStatisticsResponse, 0, len(merged))
+	for key, stat := range merged {
+		sum := 0
+		for _, count := range counts[key] {
+			sum += count.Count
+		}
+		stat.Options = make([]GetOptionCount, 0, len(counts[key]))
+		for _, count := range counts[key] {
+			count.Percent = "0.00%"
+			if sum > 0 {
+				count.Percent = fmt.Sprintf("%.2f%%", float64(count.Count)*100/float64(sum))
+			}
+			stat.Options = append(stat.Options, *count)
+		}
+		sort.Slice(stat.Options, func(i, j int) bool {
+			return stat.Options[i].SerialNum < stat.Options[j].SerialNum
+		})
+		rankOptions(stat.Options)
+		response = append(response, *stat)
+	}
+	sort.Slice(response, func(i, j int) bool {
+		return response[i].SerialNum < response[j].SerialNum
+	})
+	return response, total, nil
+}
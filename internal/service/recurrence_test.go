@@ -0,0 +1,39 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"QA-System/internal/model"
+)
+
+func TestNextOccurrence(t *testing.T) {
+	cst := time.FixedZone("CST", 8*60*60)
+	weekly := model.Recurrence{Frequency: 1, Weekday: 1, Hour: 9}
+	monthly := model.Recurrence{Frequency: 2, MonthDay: 15, Hour: 9, Minute: 30}
+	tests := []struct {
+		name       string
+		recurrence model.Recurrence
+		after      time.Time
+		want       time.Time
+	}{
+		{"每周当天未到时间", weekly, time.Date(2024, 1, 29, 8, 0, 0, 0, cst), time.Date(2024, 1, 29, 9, 0, 0, 0, cst)},
+		{"每周当天已到时间", weekly, time.Date(2024, 1, 29, 9, 0, 0, 0, cst), time.Date(2024, 2, 5, 9, 0, 0, 0, cst)},
+		{"每周跨月", weekly, time.Date(2024, 1, 31, 10, 0, 0, 0, cst), time.Date(2024, 2, 5, 9, 0, 0, 0, cst)},
+		{"每周跨年", weekly, time.Date(2024, 12, 31, 10, 0, 0, 0, cst), time.Date(2025, 1, 6, 9, 0, 0, 0, cst)},
+		{"每周按北京时间计算", weekly, time.Date(2024, 1, 29, 0, 30, 0, 0, time.UTC),
+			time.Date(2024, 1, 29, 9, 0, 0, 0, cst)},
+		{"每月当月未到日期", monthly, time.Date(2024, 2, 10, 0, 0, 0, 0, cst), time.Date(2024, 2, 15, 9, 30, 0, 0, cst)},
+		{"每月当月已过日期", monthly, time.Date(2024, 2, 15, 9, 30, 0, 0, cst), time.Date(2024, 3, 15, 9, 30, 0, 0, cst)},
+		{"每月跨年", monthly, time.Date(2024, 12, 20, 0, 0, 0, 0, cst), time.Date(2025, 1, 15, 9, 30, 0, 0, cst)},
+		{"每月按北京时间计算", monthly, time.Date(2024, 2, 15, 2, 0, 0, 0, time.UTC),
+			time.Date(2024, 3, 15, 9, 30, 0, 0, cst)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NextOccurrence(tt.recurrence, tt.after); !got.Equal(tt.want) {
+				t.Errorf("NextOccurrence(%v) = %v, want %v", tt.after, got, tt.want)
+			}
+		})
+	}
+}
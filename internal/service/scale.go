@@ -0,0 +1,123 @@
+package service
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+
+	"QA-System/internal/model"
+)
+
+// MaxScalePoints 评分量表的最大刻度数量，统计时会列出全部刻度
+const MaxScalePoints = 101
+
+// GetScaleStatistics 量表题统计
+type GetScaleStatistics struct {
+	Count  int               `json:"count"`         // 有效作答数量
+	Mean   float64           `json:"mean"`          // 平均值
+	Median float64           `json:"median"`        // 中位数
+	NPS    *GetNPSStatistics `json:"nps,omitempty"` // NPS 统计，仅 NPS 题目返回
+}
+
+// GetNPSStatistics NPS 统计
+type GetNPSStatistics struct {
+	Promoters  int     `json:"promoters"`  // 推荐者数量(9-10分)
+	Passives   int     `json:"passives"`   // 被动者数量(7-8分)
+	Detractors int     `json:"detractors"` // 贬损者数量(0-6分)
+	Score      float64 `json:"score"`      // 净推荐值，范围 -100 到 100
+}
+
+// CheckScaleAnswer 检查量表题答案是否在范围内
+func CheckScaleAnswer(question *model.Question, answer string) error {
+	value, err := strconv.ParseFloat(answer, 64)
+	if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+		return errors.New("问题" + strconv.Itoa(question.SerialNum) + "答案不是数字")
+	}
+	if value < question.MinValue || value > question.MaxValue {
+		return errors.New("问题" + strconv.Itoa(question.SerialNum) + "答案超出范围")
+	}
+	if question.Step > 0 {
+		steps := (value - question.MinValue) / question.Step
+		if math.Abs(steps-math.Round(steps)) > 1e-6 {
+			return errors.New("问题" + strconv.Itoa(question.SerialNum) + "答案不符合步长")
+		}
+	}
+	return nil
+}
+
+// buildScaleStats 根据全部作答数值计算分布、平均值、中位数和 NPS
+func buildScaleStats(question model.Question, values []float64) GetChooseStatisticsResponse {
+	sort.Float64s(values)
+	counts := make(map[float64]int)
+	sum := 0.0
+	for _, value := range values {
+		counts[value]++
+		sum += value
+	}
+
+	// 评分和 NPS 列出全部刻度，滑块以及刻度过多的旧问卷只列出有作答的数值
+	points := make([]float64, 0)
+	if question.ScaleType != 3 && question.Step > 0 &&
+		(question.MaxValue-question.MinValue)/question.Step < MaxScalePoints {
+		for v := question.MinValue; v <= question.MaxValue+1e-9; v += question.Step {
+			points = append(points, math.Round(v*1e6)/1e6)
+		}
+	} else {
+		for value := range counts {
+			points = append(points, value)
+		}
+		sort.Float64s(points)
+	}
+
+	distribution := make([]GetOptionCount, 0, len(points))
+	for i, point := range points {
+		percent := "0.00%"
+		if len(values) > 0 {
+			percent = strconv.FormatFloat(float64(counts[point])*100/float64(len(values)), 'f', 2, 64) + "%"
+		}
+		distribution = append(distribution, GetOptionCount{
+			SerialNum: i + 1,
+			Content:   strconv.FormatFloat(point, 'f', -1, 64),
+			Count:     counts[point],
+			Percent:   percent,
+		})
+	}
+
+	scale := &GetScaleStatistics{Count: len(values)}
+	if len(values) > 0 {
+		scale.Mean = math.Round(sum/float64(len(values))*100) / 100
+		mid := len(values) / 2
+		if len(values)%2 == 0 {
+			scale.Median = (values[mid-1] + values[mid]) / 2
+		} else {
+			scale.Median = values[mid]
+		}
+	}
+	if question.ScaleType == 2 {
+		nps := &GetNPSStatistics{}
+		for _, value := range values {
+			switch {
+			case value >= 9:
+				nps.Promoters++
+			case value >= 7:
+				nps.Passives++
+			default:
+				nps.Detractors++
+			}
+		}
+		if len(values) > 0 {
+			score := float64(nps.Promoters-nps.Detractors) * 100 / float64(len(values))
+			nps.Score = math.Round(score*100) / 100
+		}
+		scale.NPS = nps
+	}
+
+	return GetChooseStatisticsResponse{
+		SerialNum:    question.SerialNum,
+		Question:     question.Subject,
+		QuestionType: question.QuestionType,
+		Options:      distribution,
+		Scale:        scale,
+	}
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"testing"
+
+	"QA-System/internal/model"
+)
+
+func TestCheckScaleAnswer(t *testing.T) {
+	rating := &model.Question{SerialNum: 1, ScaleType: 1, MinValue: 1, MaxValue: 5, Step: 1}
+	slider := &model.Question{SerialNum: 2, ScaleType: 3, MinValue: 0, MaxValue: 1, Step: 0.1}
+	tests := []struct {
+		name     string
+		question *model.Question
+		answer   string
+		wantErr  bool
+	}{
+		{"最小值", rating, "1", false},
+		{"最大值", rating, "5", false},
+		{"低于范围", rating, "0", true},
+		{"超出范围", rating, "6", true},
+		{"不符合步长", rating, "2.5", true},
+		{"非数字", rating, "abc", true},
+		{"NaN", rating, "NaN", true},
+		{"正无穷", rating, "+Inf", true},
+		{"负无穷", rating, "-Inf", true},
+		{"小数步长", slider, "0.3", false},
+		{"小数不符合步长", slider, "0.35", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckScaleAnswer(tt.question, tt.answer)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckScaleAnswer(%q) error = %v, wantErr %v", tt.answer, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBuildScaleStats(t *testing.T) {
+	tests := []struct {
+		name       string
+		question   model.Question
+		values     []float64
+		wantPoints int
+		wantMean   float64
+		wantMedian float64
+		wantNPS    float64
+	}{
+		{
+			name:       "评分列出全部刻度",
+			question:   model.Question{ScaleType: 1, MinValue: 1, MaxValue: 5, Step: 1},
+			values:     []float64{5, 1, 3, 3},
+			wantPoints: 5,
+			wantMean:   3,
+			wantMedian: 3,
+		},
+		{
+			name:       "滑块只列出有作答的数值",
+			question:   model.Question{ScaleType: 3, MinValue: 0, MaxValue: 100, Step: 1},
+			values:     []float64{10, 20, 20},
+			wantPoints: 2,
+			wantMean:   16.67,
+			wantMedian: 20,
+		},
+		{
+			name:       "刻度过多时只列出有作答的数值",
+			question:   model.Question{ScaleType: 1, MinValue: 0, MaxValue: 1e9, Step: 1e-3},
+			values:     []float64{1, 2},
+			wantPoints: 2,
+			wantMean:   1.5,
+			wantMedian: 1.5,
+		},
+		{
+			name:       "NPS",
+			question:   model.Question{ScaleType: 2, MinValue: 0, MaxValue: 10, Step: 1},
+			values:     []float64{10, 9, 8, 3},
+			wantPoints: 11,
+			wantMean:   7.5,
+			wantMedian: 8.5,
+			wantNPS:    25,
+		},
+		{
+			name:       "无作答",
+			question:   model.Question{ScaleType: 1, MinValue: 1, MaxValue: 3, Step: 1},
+			values:     []float64{},
+			wantPoints: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats := buildScaleStats(tt.question, tt.values)
+			if len(stats.Options) != tt.wantPoints {
+				t.Fatalf("len(Options) = %d, want %d", len(stats.Options), tt.wantPoints)
+			}
+			if stats.Scale.Count != len(tt.values) {
+				t.Errorf("Count = %d, want %d", stats.Scale.Count, len(tt.values))
+			}
+			if stats.Scale.Mean != tt.wantMean {
+				t.Errorf("Mean = %v, want %v", stats.Scale.Mean, tt.wantMean)
+			}
+			if stats.Scale.Median != tt.wantMedian {
+				t.Errorf("Median = %v, want %v", stats.Scale.Median, tt.wantMedian)
+			}
+			if tt.question.ScaleType == 2 && stats.Scale.NPS.Score != tt.wantNPS {
+				t.Errorf("NPS = %v, want %v", stats.Scale.NPS.Score, tt.wantNPS)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	global "QA-System/internal/global/config"
+	"QA-System/internal/model"
+	"QA-System/internal/pkg/code"
+	"QA-System/internal/pkg/redis"
+	"QA-System/pkg/extension"
+
+	"go.uber.org/zap"
+)
+
+// 问卷状态事件，通过插件管理器分发给实现了事件处理接口的插件
+const (
+	EventSurveyUnpublished = "survey_unpublished" // 问卷取消发布
+	EventSurveyPublished   = "survey_published"   // 问卷发布
+	EventSurveyClosed      = "survey_closed"      // 问卷截止
+)
+
+const schedulerLockKey = "scheduler:survey_status:lock"
+
+// StartScheduler 启动问卷定时发布、截止以及周期问卷生成的后台任务
+// 每次执行都从数据库重新计算待处理的问卷，重启后会补上停机期间错过的状态变更
+func StartScheduler() {
+	interval := time.Duration(global.Config.GetInt("scheduler.interval")) * time.Second
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			runSchedule(interval)
+			<-ticker.C
+		}
+	}()
+}
+
+// runSchedule 执行一次状态调度，多实例部署时通过 Redis 锁保证每个周期只有一个实例执行
+func runSchedule(interval time.Duration) {
+	ok, err := redis.RedisClient.SetNX(ctx, schedulerLockKey, 1, interval).Result()
+	if err != nil {
+		zap.L().Error("获取问卷调度锁失败", zap.Error(err))
+		return
+	}
+	if !ok {
+		return
+	}
+	now := time.Now()
+	spawnRecurringSurveys(now)
+	surveys, err := d.GetSurveysToPublish(ctx, now)
+	if err != nil {
+		zap.L().Error("获取待发布问卷失败", zap.Error(err))
+	}
+	for _, survey := range surveys {
+		if _, err := CheckSurveyComplete(&survey); err != nil {
+			zap.L().Warn("问卷内容不完整，跳过自动发布", zap.Int64("survey_id", survey.ID), zap.Error(err))
+			continue
+		}
+		if err := UpdateSurveyStatus(survey.ID, 2); err != nil {
+			zap.L().Error("自动发布问卷失败", zap.Int64("survey_id", survey.ID), zap.Error(err))
+		}
+	}
+	surveys, err = d.GetSurveysToClose(ctx, now)
+	if err != nil {
+		zap.L().Error("获取待截止问卷失败", zap.Error(err))
+	}
+	for _, survey := range surveys {
+		if err := UpdateSurveyStatus(survey.ID, 3); err != nil {
+			zap.L().Error("自动截止问卷失败", zap.Int64("survey_id", survey.ID), zap.Error(err))
+		}
+	}
+}
+
+// CheckSurveyComplete 检查问卷是否填写完整，手动发布、自动发布和周期问卷生成均需通过该检查
+// 不完整时返回对应的错误码和原因，查询失败时返回 code.ServerError
+func CheckSurveyComplete(survey *model.Survey) (*code.Error, error) {
+	if survey.Title == "" {
+		return code.SurveyIncomplete, errors.New("问卷信息填写不完整")
+	}
+	questions, err := d.GetQuestionsBySurveyID(ctx, survey.ID)
+	if err != nil {
+		return code.ServerError, err
+	}
+	if len(questions) == 0 {
+		return code.SurveyIncomplete, errors.New("问卷问题不存在")
+	}
+	questionMap := make(map[string]bool)
+	for _, question := range questions {
+		if question.Subject == "" {
+			return code.SurveyIncomplete, errors.New("问题" + strconv.Itoa(question.SerialNum) + "内容填写为空")
+		}
+		if questionMap[question.Subject] {
+			return code.SurveyContentRepeat, errors.New("问题题目" + question.Subject + "重复")
+		}
+		questionMap[question.Subject] = true
+		switch question.QuestionType {
+		case 1, 2, 9:
+			options, err := d.GetOptionsByQuestionID(ctx, question.ID)
+			if err != nil {
+				return code.ServerError, err
+			}
+			if len(options) < 1 {
+				return code.SurveyIncomplete, errors.New("问题" + strconv.Itoa(question.SerialNum) + "选项太少")
+			}
+			optionMap := make(map[string]bool)
+			for _, option := range options {
+				if option.Content == "" {
+					return code.SurveyIncomplete, errors.New("选项" + strconv.Itoa(option.SerialNum) + "内容未填")
+				}
+				if optionMap[option.Content] {
+					return code.SurveyContentRepeat, errors.New("选项内容" + option.Content + "重复")
+				}
+				optionMap[option.Content] = true
+			}
+		case 7:
+			options, err := d.GetOptionsByQuestionID(ctx, question.ID)
+			if err != nil {
+				return code.ServerError, err
+			}
+			rows, columns := SplitMatrixOptions(options)
+			if len(rows) < 1 || len(columns) < 1 {
+				return code.SurveyIncomplete, errors.New("问题" + strconv.Itoa(question.SerialNum) + "矩阵行或列太少")
+			}
+		}
+	}
+	return nil, nil
+}
+
+// dispatchSurveyEvent 向插件分发问卷状态事件
+func dispatchSurveyEvent(id int64, status int) {
+	events := map[int]string{
+		1: EventSurveyUnpublished,
+		2: EventSurveyPublished,
+		3: EventSurveyClosed,
+	}
+	event, ok := events[status]
+	if !ok {
+		return
+	}
+	survey, err := d.GetSurveyByID(ctx, id)
+	if err != nil {
+		zap.L().Error("获取问卷信息失败", zap.Int64("survey_id", id), zap.Error(err))
+		return
+	}
+	extension.DispatchEvent(event, map[string]any{
+		"survey_id":    survey.ID,
+		"survey_title": survey.Title,
+		"user_id":      survey.UserID,
+		"status":       status,
+		"timestamp":    time.Now().UnixNano(),
+	})
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"QA-System/internal/dao"
+	"QA-System/internal/model"
+)
+
+// GetSectionsBySurveyID 根据问卷ID获取分页
+func GetSectionsBySurveyID(sid int64) ([]model.Section, error) {
+	return d.GetSectionsBySurveyID(ctx, sid)
+}
+
+// GetSectionResponse 构建分页响应，每个分页附带按题目列表顺序排列的题目序号
+func GetSectionResponse(sections []model.Section, questions []model.Question) []map[string]any {
+	sectionResponse := make([]map[string]any, 0, len(sections))
+	for _, section := range sections {
+		serials := make([]int, 0)
+		for _, q := range questions {
+			if q.SectionNum == section.SerialNum {
+				serials = append(serials, q.SerialNum)
+			}
+		}
+		sectionResponse = append(sectionResponse, map[string]any{
+			"serial_num":        section.SerialNum,
+			"title":             section.Title,
+			"desc":              section.Desc,
+			"shuffle_questions": section.ShuffleQuestions,
+			"questions":         serials,
+		})
+	}
+	return sectionResponse
+}
+
+func createSections(db dao.Daos, sections []dao.Section, sid int64) error {
+	for _, section := range sections {
+		var s model.Section
+		s.SurveyID = sid
+		s.SerialNum = section.SerialNum
+		s.Title = section.Title
+		s.Desc = section.Desc
+		s.ShuffleQuestions = section.ShuffleQuestions
+		err := db.CreateSection(ctx, s)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"hash/fnv"
+	"math/rand"
+	"sort"
+	"strconv"
+
+	"QA-System/internal/model"
+)
+
+// ArrangeQuestions 按序号排列题目，并根据问卷和分页的设置打乱题目顺序
+// seed 为空时不打乱，同一 seed 得到的顺序固定，分页之间的顺序保持不变
+func ArrangeQuestions(survey *model.Survey, sections []model.Section, questions []model.Question,
+	seed string) []model.Question {
+	sorted := make([]model.Question, len(questions))
+	copy(sorted, questions)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].SerialNum < sorted[j].SerialNum
+	})
+	if seed == "" {
+		return sorted
+	}
+
+	shuffleSection := make(map[int]bool)
+	for _, section := range sections {
+		shuffleSection[section.SerialNum] = section.ShuffleQuestions
+	}
+	// 分页内的题目序号连续，逐段打乱
+	for start := 0; start < len(sorted); {
+		end := start
+		for end < len(sorted) && sorted[end].SectionNum == sorted[start].SectionNum {
+			end++
+		}
+		if survey.ShuffleQuestions || shuffleSection[sorted[start].SectionNum] {
+			group := sorted[start:end]
+			r := newSeededRand(seed, "survey:"+strconv.FormatInt(survey.ID, 10)+
+				":section:"+strconv.Itoa(sorted[start].SectionNum))
+			r.Shuffle(len(group), func(i, j int) {
+				group[i], group[j] = group[j], group[i]
+			})
+		}
+		start = end
+	}
+	return sorted
+}
+
+// ArrangeOptions 按序号排列选项，题目开启乱序时根据 seed 打乱选项顺序，矩阵题的列不参与打乱
+func ArrangeOptions(question model.Question, options []model.Option, seed string) []model.Option {
+	sorted := make([]model.Option, len(options))
+	copy(sorted, options)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].IsColumn != sorted[j].IsColumn {
+			return !sorted[i].IsColumn
+		}
+		return sorted[i].SerialNum < sorted[j].SerialNum
+	})
+	if seed == "" || !question.ShuffleOptions {
+		return sorted
+	}
+	rows := 0
+	for rows < len(sorted) && !sorted[rows].IsColumn {
+		rows++
+	}
+	group := sorted[:rows]
+	r := newSeededRand(seed, "question:"+strconv.Itoa(question.ID))
+	r.Shuffle(len(group), func(i, j int) {
+		group[i], group[j] = group[j], group[i]
+	})
+	return sorted
+}
+
+// GetRespondentSeed 获取答题者的乱序种子，优先使用学号，未登录时使用 IP
+func GetRespondentSeed(studentID string, ip string) string {
+	if studentID != "" {
+		return "stu:" + studentID
+	}
+	return "ip:" + ip
+}
+
+func newSeededRand(seed string, key string) *rand.Rand {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(seed + "|" + key))
+	// 乱序仅用于展示，不需要密码学安全的随机数
+	return rand.New(rand.NewSource(int64(h.Sum64()))) //nolint:gosec
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+
+	"QA-System/internal/model"
+)
+
+// questionSerials 获取题目序号列表
+func questionSerials(questions []model.Question) []int {
+	serials := make([]int, 0, len(questions))
+	for _, question := range questions {
+		serials = append(serials, question.SerialNum)
+	}
+	return serials
+}
+
+// serialRange 生成 from 到 to 的连续序号
+func serialRange(from, to int) []int {
+	serials := make([]int, 0, to-from+1)
+	for i := from; i <= to; i++ {
+		serials = append(serials, i)
+	}
+	return serials
+}
+
+func TestArrangeQuestions(t *testing.T) {
+	// 第1页为题目1-10，第2页为题目11-20，输入顺序倒序
+	questions := make([]model.Question, 0, 20)
+	for serial := 20; serial >= 1; serial-- {
+		questions = append(questions, model.Question{ID: serial, SerialNum: serial, SectionNum: (serial-1)/10 + 1})
+	}
+	survey := &model.Survey{ID: 1}
+	shuffleAll := &model.Survey{ID: 1, ShuffleQuestions: true}
+	shuffleFirst := []model.Section{{SerialNum: 1, ShuffleQuestions: true}, {SerialNum: 2}}
+
+	t.Run("没有种子时按序号排列", func(t *testing.T) {
+		got := questionSerials(ArrangeQuestions(shuffleAll, nil, questions, ""))
+		if !reflect.DeepEqual(got, serialRange(1, 20)) {
+			t.Errorf("ArrangeQuestions() = %v", got)
+		}
+	})
+	t.Run("未开启乱序时按序号排列", func(t *testing.T) {
+		got := questionSerials(ArrangeQuestions(survey, nil, questions, "stu:1"))
+		if !reflect.DeepEqual(got, serialRange(1, 20)) {
+			t.Errorf("ArrangeQuestions() = %v", got)
+		}
+	})
+	t.Run("同一种子顺序相同", func(t *testing.T) {
+		first := questionSerials(ArrangeQuestions(shuffleAll, nil, questions, "stu:1"))
+		second := questionSerials(ArrangeQuestions(shuffleAll, nil, questions, "stu:1"))
+		if !reflect.DeepEqual(first, second) {
+			t.Errorf("ArrangeQuestions() = %v, then %v", first, second)
+		}
+		if reflect.DeepEqual(first, serialRange(1, 20)) {
+			t.Errorf("ArrangeQuestions() did not shuffle: %v", first)
+		}
+	})
+	t.Run("不同种子顺序不同", func(t *testing.T) {
+		first := questionSerials(ArrangeQuestions(shuffleAll, nil, questions, "stu:1"))
+		second := questionSerials(ArrangeQuestions(shuffleAll, nil, questions, "stu:2"))
+		if reflect.DeepEqual(first, second) {
+			t.Errorf("ArrangeQuestions() = %v for both seeds", first)
+		}
+	})
+	tests := []struct {
+		name       string
+		survey     *model.Survey
+		sections   []model.Section
+		wantSecond []int // nil 表示第2页也被打乱
+	}{
+		{"问卷乱序时各页分别打乱", shuffleAll, nil, nil},
+		{"只打乱开启乱序的分页", survey, shuffleFirst, serialRange(11, 20)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := questionSerials(ArrangeQuestions(tt.survey, tt.sections, questions, "stu:1"))
+			first, second := slices.Clone(got[:10]), slices.Clone(got[10:])
+			slices.Sort(first)
+			if !reflect.DeepEqual(first, serialRange(1, 10)) || reflect.DeepEqual(got[:10], serialRange(1, 10)) {
+				t.Errorf("第1页 = %v", got[:10])
+			}
+			if tt.wantSecond != nil {
+				if !reflect.DeepEqual(second, tt.wantSecond) {
+					t.Errorf("第2页 = %v, want %v", second, tt.wantSecond)
+				}
+				return
+			}
+			slices.Sort(second)
+			if !reflect.DeepEqual(second, serialRange(11, 20)) || reflect.DeepEqual(got[10:], serialRange(11, 20)) {
+				t.Errorf("第2页 = %v", got[10:])
+			}
+		})
+	}
+}
+
+func TestArrangeOptions(t *testing.T) {
+	// 矩阵题：行1-8，列1-3，输入顺序倒序
+	options := make([]model.Option, 0, 11)
+	for serial := 3; serial >= 1; serial-- {
+		options = append(options, model.Option{SerialNum: serial, IsColumn: true})
+	}
+	for serial := 8; serial >= 1; serial-- {
+		options = append(options, model.Option{SerialNum: serial})
+	}
+	arrange := func(question model.Question, seed string) ([]int, []int) {
+		rows, columns := make([]int, 0), make([]int, 0)
+		for _, option := range ArrangeOptions(question, options, seed) {
+			if option.IsColumn {
+				columns = append(columns, option.SerialNum)
+			} else if len(columns) == 0 {
+				rows = append(rows, option.SerialNum)
+			} else {
+				t.Fatalf("行出现在列之后")
+			}
+		}
+		return rows, columns
+	}
+	shuffle := model.Question{ID: 1, ShuffleOptions: true}
+
+	rows, columns := arrange(model.Question{ID: 1}, "stu:1")
+	if !reflect.DeepEqual(rows, serialRange(1, 8)) || !reflect.DeepEqual(columns, serialRange(1, 3)) {
+		t.Errorf("未开启乱序 = %v %v", rows, columns)
+	}
+	rows, columns = arrange(shuffle, "")
+	if !reflect.DeepEqual(rows, serialRange(1, 8)) {
+		t.Errorf("没有种子 = %v", rows)
+	}
+	rows, columns = arrange(shuffle, "stu:1")
+	again, _ := arrange(shuffle, "stu:1")
+	other, _ := arrange(shuffle, "stu:2")
+	if !reflect.DeepEqual(rows, again) {
+		t.Errorf("同一种子 = %v, then %v", rows, again)
+	}
+	if reflect.DeepEqual(rows, other) {
+		t.Errorf("不同种子顺序相同 = %v", rows)
+	}
+	if !reflect.DeepEqual(columns, serialRange(1, 3)) {
+		t.Errorf("矩阵列 = %v, want %v", columns, serialRange(1, 3))
+	}
+	sorted := slices.Clone(rows)
+	slices.Sort(sorted)
+	if !reflect.DeepEqual(sorted, serialRange(1, 8)) || reflect.DeepEqual(rows, serialRange(1, 8)) {
+		t.Errorf("行 = %v", rows)
+	}
+}
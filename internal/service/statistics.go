@@ -0,0 +1,205 @@
+package service
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"QA-System/internal/dao"
+	"QA-System/internal/model"
+)
+
+// GetSurveyStatistics 使用聚合管道统计问卷中选择、矩阵、量表和排序题的作答情况，按题目序号排列
+// 历史版本的答案按题目标识计入当前版本的题目
+func GetSurveyStatistics(sid int64) ([]GetChooseStatisticsResponse, error) {
+	questions, err := d.GetQuestionsBySurveyID(ctx, sid)
+	if err != nil {
+		return nil, err
+	}
+	allQuestions, err := d.GetAllQuestionsBySurveyID(ctx, sid)
+	if err != nil {
+		return nil, err
+	}
+	keyMap := make(map[string]int)
+	for _, question := range questions {
+		keyMap[QuestionKey(question)] = question.ID
+	}
+	// 各版本题目ID到当前版本题目ID的映射
+	idMap := make(map[int]int)
+	ids := make([]int, 0, len(allQuestions))
+	for _, question := range allQuestions {
+		if id, ok := keyMap[QuestionKey(question)]; ok {
+			idMap[question.ID] = id
+			ids = append(ids, question.ID)
+		}
+	}
+	parts, err := d.CountAnswerParts(ctx, sid, ids)
+	if err != nil {
+		return nil, err
+	}
+	partsMap := make(map[int][]dao.AnswerPartCount)
+	for _, part := range parts {
+		id := idMap[part.QuestionID]
+		partsMap[id] = append(partsMap[id], part)
+	}
+
+	response := make([]GetChooseStatisticsResponse, 0, len(questions))
+	for _, question := range questions {
+		switch question.QuestionType {
+		case 1, 2, 7, 8, 9:
+		default:
+			continue
+		}
+		options, err := d.GetOptionsByQuestionID(ctx, question.ID)
+		if err != nil {
+			return nil, err
+		}
+		switch question.QuestionType {
+		case 1, 2:
+			response = append(response, buildChoiceStats(question, options, partsMap[question.ID]))
+		case 7:
+			response = append(response, buildMatrixStats(question, options, getMatrixCounts(partsMap[question.ID])))
+		case 8:
+			response = append(response, buildScaleStats(question, getScaleValues(partsMap[question.ID])))
+		case 9:
+			response = append(response, GetChooseStatisticsResponse{
+				SerialNum:    question.SerialNum,
+				Question:     question.Subject,
+				QuestionType: question.QuestionType,
+				Options:      make([]GetOptionCount, 0),
+				Ranking:      buildRankingStats(options, getRankingPositions(partsMap[question.ID])),
+			})
+		}
+	}
+	sort.Slice(response, func(i, j int) bool {
+		return response[i].SerialNum < response[j].SerialNum
+	})
+	return response, nil
+}
+
+// CountAnswerSheets 统计问卷的有效答卷数量
+func CountAnswerSheets(sid int64) (int64, error) {
+	return d.CountAnswerSheetsBySurveyID(ctx, sid)
+}
+
+// buildChoiceStats 生成选择题各选项的数量、占比和排名，不属于任何选项的答案计入“其他”
+func buildChoiceStats(question model.Question, options []model.Option,
+	parts []dao.AnswerPartCount) GetChooseStatisticsResponse {
+	sort.Slice(options, func(i, j int) bool {
+		return options[i].SerialNum < options[j].SerialNum
+	})
+	contentMap := make(map[string]int)
+	for _, option := range options {
+		contentMap[option.Content] = option.SerialNum
+	}
+	counts := make(map[int]int)
+	total := 0
+	for _, part := range parts {
+		// “其他”选项，统一用 SerialNum = 0 表示
+		counts[contentMap[part.Content]] += part.Count
+		total += part.Count
+	}
+
+	optionCounts := make([]GetOptionCount, 0, len(options)+1)
+	if question.OtherOption {
+		optionCounts = append(optionCounts, GetOptionCount{
+			SerialNum: 0,
+			Content:   "其他",
+			Count:     counts[0],
+			Percent:   formatPercent(counts[0], total),
+		})
+	}
+	for _, option := range options {
+		optionCounts = append(optionCounts, GetOptionCount{
+			SerialNum: option.SerialNum,
+			Content:   option.Content,
+			Count:     counts[option.SerialNum],
+			Percent:   formatPercent(counts[option.SerialNum], total),
+		})
+	}
+	rankOptions(optionCounts)
+	return GetChooseStatisticsResponse{
+		SerialNum:    question.SerialNum,
+		Question:     question.Subject,
+		QuestionType: question.QuestionType,
+		Options:      optionCounts,
+	}
+}
+
+// rankOptions 按数量降序为选项排名，数量相同排名相同
+func rankOptions(options []GetOptionCount) {
+	sorted := make([]GetOptionCount, len(options))
+	copy(sorted, options)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Count == sorted[j].Count {
+			return sorted[i].SerialNum < sorted[j].SerialNum
+		}
+		return sorted[i].Count > sorted[j].Count
+	})
+	rankMap := make(map[int]int)
+	currentRank := 1
+	for i := range sorted {
+		if i > 0 && sorted[i].Count < sorted[i-1].Count {
+			currentRank = i + 1
+		}
+		rankMap[sorted[i].SerialNum] = currentRank
+	}
+	for i := range options {
+		options[i].Rank = rankMap[options[i].SerialNum]
+	}
+}
+
+// getMatrixCounts 将矩阵题的 "行序号:列序号" 答案汇总为各行各列的数量
+func getMatrixCounts(parts []dao.AnswerPartCount) map[int]map[int]int {
+	counts := make(map[int]map[int]int)
+	for _, part := range parts {
+		row, column, ok := strings.Cut(part.Content, ":")
+		if !ok {
+			continue
+		}
+		r, err := strconv.Atoi(row)
+		if err != nil {
+			continue
+		}
+		col, err := strconv.Atoi(column)
+		if err != nil {
+			continue
+		}
+		if counts[r] == nil {
+			counts[r] = make(map[int]int)
+		}
+		counts[r][col] += part.Count
+	}
+	return counts
+}
+
+// getScaleValues 将量表题各数值的数量展开为全部作答数值
+func getScaleValues(parts []dao.AnswerPartCount) []float64 {
+	values := make([]float64, 0)
+	for _, part := range parts {
+		value, err := strconv.ParseFloat(part.Content, 64)
+		if err != nil {
+			continue
+		}
+		for i := 0; i < part.Count; i++ {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// getRankingPositions 将排序题答案汇总为各选项在各名次的数量
+func getRankingPositions(parts []dao.AnswerPartCount) map[int]map[int]int {
+	positions := make(map[int]map[int]int)
+	for _, part := range parts {
+		serial, err := strconv.Atoi(part.Content)
+		if err != nil {
+			continue
+		}
+		if positions[serial] == nil {
+			positions[serial] = make(map[int]int)
+		}
+		positions[serial][part.Position] += part.Count
+	}
+	return positions
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"QA-System/internal/dao"
+	"QA-System/internal/model"
+)
+
+func TestBuildChoiceStats(t *testing.T) {
+	options := []model.Option{
+		{SerialNum: 2, Content: "B"},
+		{SerialNum: 1, Content: "A"},
+	}
+	tests := []struct {
+		name     string
+		question model.Question
+		parts    []dao.AnswerPartCount
+		want     []GetOptionCount
+	}{
+		{
+			name:     "无作答",
+			question: model.Question{QuestionType: 1},
+			parts:    nil,
+			want: []GetOptionCount{
+				{SerialNum: 1, Content: "A", Percent: "0.00%", Rank: 1},
+				{SerialNum: 2, Content: "B", Percent: "0.00%", Rank: 1},
+			},
+		},
+		{
+			// 两份答卷：A┋B 和 B┋A
+			name:     "同一选项在不同位置合并计数",
+			question: model.Question{QuestionType: 2},
+			parts: []dao.AnswerPartCount{
+				{Content: "A", Position: 0, Count: 1},
+				{Content: "B", Position: 1, Count: 1},
+				{Content: "B", Position: 0, Count: 1},
+				{Content: "A", Position: 1, Count: 1},
+			},
+			want: []GetOptionCount{
+				{SerialNum: 1, Content: "A", Count: 2, Percent: "50.00%", Rank: 1},
+				{SerialNum: 2, Content: "B", Count: 2, Percent: "50.00%", Rank: 1},
+			},
+		},
+		{
+			name:     "不属于任何选项的答案计入其他",
+			question: model.Question{QuestionType: 1, OtherOption: true},
+			parts: []dao.AnswerPartCount{
+				{Content: "B", Count: 2},
+				{Content: "自定义1", Count: 1},
+				{Content: "自定义2", Count: 1},
+			},
+			want: []GetOptionCount{
+				{SerialNum: 0, Content: "其他", Count: 2, Percent: "50.00%", Rank: 1},
+				{SerialNum: 1, Content: "A", Count: 0, Percent: "0.00%", Rank: 3},
+				{SerialNum: 2, Content: "B", Count: 2, Percent: "50.00%", Rank: 1},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := buildChoiceStats(tt.question, options, tt.parts)
+			if !reflect.DeepEqual(got.Options, tt.want) {
+				t.Errorf("buildChoiceStats() = %+v, want %+v", got.Options, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetMatrixCounts(t *testing.T) {
+	tests := []struct {
+		name  string
+		parts []dao.AnswerPartCount
+		want  map[int]map[int]int
+	}{
+		{"无作答", nil, map[int]map[int]int{}},
+		{
+			name: "按行列汇总",
+			parts: []dao.AnswerPartCount{
+				{Content: "1:2", Position: 0, Count: 3},
+				{Content: "2:1", Position: 1, Count: 1},
+				{Content: "1:2", Position: 1, Count: 2},
+				{Content: "1:3", Position: 0, Count: 1},
+			},
+			want: map[int]map[int]int{1: {2: 5, 3: 1}, 2: {1: 1}},
+		},
+		{
+			name: "跳过格式错误的答案",
+			parts: []dao.AnswerPartCount{
+				{Content: "1", Count: 1},
+				{Content: "a:1", Count: 1},
+				{Content: "1:b", Count: 1},
+				{Content: "2:2", Count: 1},
+			},
+			want: map[int]map[int]int{2: {2: 1}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getMatrixCounts(tt.parts); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getMatrixCounts() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetRankingPositions(t *testing.T) {
+	tests := []struct {
+		name  string
+		parts []dao.AnswerPartCount
+		want  map[int]map[int]int
+	}{
+		{"无作答", nil, map[int]map[int]int{}},
+		{
+			// 三份答卷：1┋2、2┋1、1┋2
+			name: "按选项和名次汇总",
+			parts: []dao.AnswerPartCount{
+				{Content: "1", Position: 0, Count: 2},
+				{Content: "2", Position: 1, Count: 2},
+				{Content: "2", Position: 0, Count: 1},
+				{Content: "1", Position: 1, Count: 1},
+			},
+			want: map[int]map[int]int{1: {0: 2, 1: 1}, 2: {0: 1, 1: 2}},
+		},
+		{
+			name:  "跳过非序号的答案",
+			parts: []dao.AnswerPartCount{{Content: "a", Count: 1}, {Content: "3", Position: 2, Count: 1}},
+			want:  map[int]map[int]int{3: {2: 1}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getRankingPositions(tt.parts); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getRankingPositions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package service
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	"QA-System/internal/dao"
+	"QA-System/internal/model"
+)
+
+// GetSurveyDefinition 根据已有问卷生成问卷定义快照
+func GetSurveyDefinition(survey *model.Survey) (dao.SurveyDefinition, error) {
+	var definition dao.SurveyDefinition
+	questions, err := d.GetQuestionsBySurveyID(ctx, survey.ID)
+	if err != nil {
+		return definition, err
+	}
+	sort.Slice(questions, func(i, j int) bool {
+		return questions[i].SerialNum < questions[j].SerialNum
+	})
+	questionList := make([]dao.QuestionList, 0, len(questions))
+	for _, question := range questions {
+		options, err := d.GetOptionsByQuestionID(ctx, question.ID)
+		if err != nil {
+			return definition, err
+		}
+		questionList = append(questionList, getQuestionList(question, options))
+	}
+	rules, err := d.GetRulesBySurveyID(ctx, survey.ID)
+	if err != nil {
+		return definition, err
+	}
+	logic := make([]dao.Rule, 0, len(rules))
+	for _, rule := range rules {
+		logic = append(logic, dao.Rule{
+			SerialNum:     rule.SerialNum,
+			MatchType:     rule.MatchType,
+			OptionSerial:  rule.OptionSerial,
+			Text:          rule.Text,
+			Action:        rule.Action,
+			TargetSerial:  rule.TargetSerial,
+			TargetSection: rule.TargetSection,
+		})
+	}
+	sections, err := d.GetSectionsBySurveyID(ctx, survey.ID)
+	if err != nil {
+		return definition, err
+	}
+	sectionList := make([]dao.Section, 0, len(sections))
+	for _, section := range sections {
+		sectionList = append(sectionList, dao.Section{
+			SerialNum:        section.SerialNum,
+			Title:            section.Title,
+			Desc:             section.Desc,
+			ShuffleQuestions: section.ShuffleQuestions,
+		})
+	}
+	quotas, err := d.GetQuotasBySurveyID(ctx, survey.ID)
+	if err != nil {
+		return definition, err
+	}
+	quotaList := make([]dao.Quota, 0, len(quotas))
+	for _, quota := range quotas {
+		quotaList = append(quotaList, dao.Quota{Field: quota.Field, Value: quota.Value, Limit: quota.Limit})
+	}
+	translations, err := GetTranslations(survey.ID)
+	if err != nil {
+		return definition, err
+	}
+	location := time.FixedZone("CST", 8*60*60)
+	definition.SurveyType = survey.Type
+	definition.BaseConfig = dao.BaseConfig{
+		StartTime:        survey.StartTime.In(location).Format(time.RFC3339),
+		EndTime:          survey.Deadline.In(location).Format(time.RFC3339),
+		DailyLimit:       survey.DailyLimit,
+		SumLimit:         survey.SumLimit,
+		Verify:           survey.Verify,
+		UndergradOnly:    survey.UndergradOnly,
+		NeedNotify:       survey.NeedNotify,
+		ShuffleQuestions: survey.ShuffleQuestions,
+		ShowScore:        survey.ShowScore,
+		AutoPublish:      survey.AutoPublish,
+		HideLiveResults:  survey.HideLiveResults,
+		ResultVisibility: survey.ResultVisibility,
+		Quotas:           quotaList,
+	}
+	definition.QuestionConfig = dao.QuestionConfig{
+		Title:        survey.Title,
+		Desc:         survey.Desc,
+		QuestionList: questionList,
+		Logic:        logic,
+		Sections:     sectionList,
+	}
+	definition.Translations = translations
+	return definition, nil
+}
+
+// getQuestionList 将题目及其选项转换为问题配置
+func getQuestionList(question model.Question, options []model.Option) dao.QuestionList {
+	sort.Slice(options, func(i, j int) bool {
+		return options[i].SerialNum < options[j].SerialNum
+	})
+	rows, columns := SplitMatrixOptions(options)
+	q := dao.QuestionList{
+		Key:         QuestionKey(question),
+		SerialNum:   question.SerialNum,
+		SectionNum:  question.SectionNum,
+		Subject:     question.Subject,
+		Description: question.Description,
+		Img:         question.Img,
+		QuestionSetting: dao.QuestionSetting{
+			Required:       question.Required,
+			Unique:         question.Unique,
+			OtherOption:    question.OtherOption,
+			QuestionType:   question.QuestionType,
+			Reg:            question.Reg,
+			ShuffleOptions: question.ShuffleOptions,
+			MaximumOption:  question.MaximumOption,
+			MinimumOption:  question.MinimumOption,
+			InputType:      question.InputType,
+			ScaleType:      question.ScaleType,
+			MinValue:       question.MinValue,
+			MaxValue:       question.MaxValue,
+			Step:           question.Step,
+			Score:          question.Score,
+			AnswerKey:      question.AnswerKey,
+		},
+		Options: make([]dao.Option, 0, len(rows)),
+		Columns: make([]dao.Option, 0, len(columns)),
+	}
+	for _, option := range rows {
+		q.Options = append(q.Options, dao.Option{
+			SerialNum:   option.SerialNum,
+			Content:     option.Content,
+			Description: option.Description,
+			Img:         option.Img,
+			Capacity:    option.Capacity,
+			IsCorrect:   option.IsCorrect,
+			Score:       option.Score,
+		})
+	}
+	for _, column := range columns {
+		q.Columns = append(q.Columns, dao.Option{
+			SerialNum:   column.SerialNum,
+			Content:     column.Content,
+			Description: column.Description,
+			Img:         column.Img,
+		})
+	}
+	return q
+}
+
+// CreateSurveyByDefinition 根据问卷定义创建一份未发布的问卷，返回新问卷的ID
+// 新问卷的时间可能沿用旧值，不开启自动发布以免创建后立即发布
+func CreateSurveyByDefinition(uid int, definition dao.SurveyDefinition) (int64, error) {
+	base := definition.BaseConfig
+	base.AutoPublish = false
+	// 问卷和翻译在同一事务中创建，避免复制、导入后问卷缺少翻译
+	var sid int64
+	err := d.Transaction(ctx, func(tx dao.Daos) error {
+		var err error
+		sid, err = createSurvey(tx, uid, 1, definition.SurveyType, base, definition.QuestionConfig)
+		if err != nil {
+			return err
+		}
+		return saveTranslations(tx, sid, definition.Translations)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return sid, nil
+}
+
+// CreateTemplate 创建问卷模板
+func CreateTemplate(uid int, title string, shared bool, definition dao.SurveyDefinition) error {
+	content, err := json.Marshal(definition)
+	if err != nil {
+		return err
+	}
+	err = d.CreateTemplate(ctx, model.Template{
+		UserID:   uid,
+		Title:    title,
+		IsShared: shared,
+		Content:  string(content),
+	})
+	return err
+}
+
+// CreateSurveyByTemplate 根据模板中的问卷定义创建问卷，图片重新上传，避免与原问卷共用图片
+func CreateSurveyByTemplate(uid int, definition dao.SurveyDefinition) (int64, error) {
+	return createSurveyWithImages(uid, definition)
+}
+
+// GetTemplateByID 根据ID获取问卷模板
+func GetTemplateByID(id int) (*model.Template, error) {
+	template, err := d.GetTemplateByID(ctx, id)
+	return template, err
+}
+
+// GetTemplatesByUserID 获取用户可见的问卷模板
+func GetTemplatesByUserID(uid int) ([]model.Template, error) {
+	templates, err := d.GetTemplatesByUserID(ctx, uid)
+	return templates, err
+}
+
+// GetTemplateDefinition 解析问卷模板中的问卷定义
+func GetTemplateDefinition(template *model.Template) (dao.SurveyDefinition, error) {
+	var definition dao.SurveyDefinition
+	err := json.Unmarshal([]byte(template.Content), &definition)
+	return definition, err
+}
+
+// DeleteTemplate 删除问卷模板
+func DeleteTemplate(id int) error {
+	err := d.DeleteTemplate(ctx, id)
+	return err
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"QA-System/internal/dao"
+)
+
+// ErrTimelineQuestion 筛选答卷的题目不存在
+var ErrTimelineQuestion = errors.New("筛选的题目不存在")
+
+// TimelinePoint 答卷时间分布中的一个时间段
+type TimelinePoint struct {
+	Time       string `json:"time"`       // 时间段，按小时为 "2006-01-02 15"，按天为 "2006-01-02"
+	Count      int    `json:"count"`      // 该时间段的答卷数量
+	Cumulative int    `json:"cumulative"` // 截至该时间段的累计答卷数量
+}
+
+// GetSubmissionTimeline 按小时或天统计问卷的答卷数量及累计曲线，返回各时间段和答卷总数
+// serialNum 不为0时只统计该题答案包含 answer 的答卷，历史版本的答卷按题目标识匹配
+// 首尾之间没有答卷的时间段补0，便于直接绘制曲线
+func GetSubmissionTimeline(sid int64, interval string, serialNum int, answer string) ([]TimelinePoint, int, error) {
+	var filter *dao.AnswerFilter
+	if serialNum != 0 {
+		questions, err := d.GetQuestionsBySurveyID(ctx, sid)
+		if err != nil {
+			return nil, 0, err
+		}
+		key := ""
+		for _, question := range questions {
+			if question.SerialNum == serialNum {
+				key = QuestionKey(question)
+				break
+			}
+		}
+		if key == "" {
+			return nil, 0, ErrTimelineQuestion
+		}
+		allQuestions, err := d.GetAllQuestionsBySurveyID(ctx, sid)
+		if err != nil {
+			return nil, 0, err
+		}
+		filter = &dao.AnswerFilter{Content: answer}
+		for _, question := range allQuestions {
+			if QuestionKey(question) == key {
+				filter.QuestionIDs = append(filter.QuestionIDs, question.ID)
+			}
+		}
+	}
+	buckets, err := d.CountAnswerSheetsByTime(ctx, sid, interval, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	layout := time.DateOnly
+	next := func(t time.Time) time.Time { return t.AddDate(0, 0, 1) }
+	if interval == "hour" {
+		layout = "2006-01-02 15"
+		next = func(t time.Time) time.Time { return t.Add(time.Hour) }
+	}
+	points := make([]TimelinePoint, 0, len(buckets))
+	total := 0
+	var last time.Time
+	for _, bucket := range buckets {
+		current, err := time.ParseInLocation(layout, bucket.Time, time.Local)
+		if err == nil && !last.IsZero() {
+			for t := next(last); t.Before(current); t = next(t) {
+				points = append(points, TimelinePoint{Time: t.Format(layout), Cumulative: total})
+			}
+		}
+		if err == nil {
+			last = current
+		}
+		total += bucket.Count
+		points = append(points, TimelinePoint{Time: bucket.Time, Count: bucket.Count, Cumulative: total})
+	}
+	return points, total, nil
+}
@@ -0,0 +1,343 @@
+package service
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"QA-System/internal/dao"
+	"QA-System/internal/model"
+)
+
+// translationKey 翻译的定位，题目标识为空时为问卷本身，选项序号为0时为题目本身
+type translationKey struct {
+	question string
+	serial   int
+	column   bool
+}
+
+// Translator 问卷某一语言的翻译，没有翻译的内容保持原文
+type Translator map[translationKey]model.Translation
+
+// GetTranslator 获取问卷某一语言的翻译，locale 为空时返回空翻译
+func GetTranslator(sid int64, locale string) (Translator, error) {
+	translator := make(Translator)
+	if locale == "" {
+		return translator, nil
+	}
+	translations, err := d.GetTranslationsByLocale(ctx, sid, locale)
+	if err != nil {
+		return nil, err
+	}
+	for _, translation := range translations {
+		key := translationKey{translation.QuestionKey, translation.OptionSerial, translation.IsColumn}
+		translator[key] = translation
+	}
+	return translator, nil
+}
+
+// translate 用翻译替换原文，翻译为空时保留原文
+func (t Translator) translate(key translationKey, content, description *string) {
+	translation, ok := t[key]
+	if !ok {
+		return
+	}
+	if translation.Content != "" {
+		*content = translation.Content
+	}
+	if translation.Description != "" {
+		*description = translation.Description
+	}
+}
+
+// TranslateSurvey 翻译问卷标题和描述
+func (t Translator) TranslateSurvey(survey *model.Survey) {
+	t.translate(translationKey{}, &survey.Title, &survey.Desc)
+}
+
+// TranslateQuestion 翻译题目和题目描述
+func (t Translator) TranslateQuestion(question *model.Question) {
+	t.translate(translationKey{question: QuestionKey(*question)}, &question.Subject, &question.Description)
+}
+
+// TranslateOptions 翻译题目的选项内容和选项描述
+func (t Translator) TranslateOptions(question model.Question, options []model.Option) {
+	key := QuestionKey(question)
+	for i := range options {
+		t.translate(translationKey{key, options[i].SerialNum, options[i].IsColumn},
+			&options[i].Content, &options[i].Description)
+	}
+}
+
+// MatchLocale 根据请求指定的语言或 Accept-Language 从问卷已有的翻译中选择语言
+// 没有匹配的翻译时返回空字符串，即使用问卷原文
+func MatchLocale(locales []string, lang string, acceptLanguage string) string {
+	if len(locales) == 0 {
+		return ""
+	}
+	candidates := parseAcceptLanguage(acceptLanguage)
+	if lang != "" {
+		candidates = append([]string{lang}, candidates...)
+	}
+	for _, candidate := range candidates {
+		// 先精确匹配，再按主语言匹配，如 en-US 匹配 en
+		for _, locale := range locales {
+			if strings.EqualFold(locale, candidate) {
+				return locale
+			}
+		}
+		primary := primaryLanguage(candidate)
+		for _, locale := range locales {
+			if strings.EqualFold(primaryLanguage(locale), primary) {
+				return locale
+			}
+		}
+	}
+	return ""
+}
+
+// parseAcceptLanguage 解析 Accept-Language 请求头，按权重从高到低返回语言
+func parseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+	tags := make([]weighted, 0)
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		tag := strings.TrimSpace(fields[0])
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if value, ok := strings.CutPrefix(param, "q="); ok {
+				if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		if q > 0 {
+			tags = append(tags, weighted{tag, q})
+		}
+	}
+	sort.SliceStable(tags, func(i, j int) bool {
+		return tags[i].q > tags[j].q
+	})
+	result := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		result = append(result, tag.tag)
+	}
+	return result
+}
+
+// primaryLanguage 获取语言标签的主语言部分
+func primaryLanguage(tag string) string {
+	tag, _, _ = strings.Cut(strings.ReplaceAll(tag, "_", "-"), "-")
+	return tag
+}
+
+// SaveTranslation 保存问卷某一语言的翻译，覆盖该语言原有的翻译
+func SaveTranslation(sid int64, translation dao.Translation) error {
+	err := d.DeleteTranslationsByLocale(ctx, sid, translation.Locale)
+	if err != nil {
+		return err
+	}
+	return d.CreateTranslations(ctx, getTranslationRecords(sid, translation))
+}
+
+// getTranslationRecords 将问卷某一语言的翻译展开为翻译记录，跳过空白的翻译
+func getTranslationRecords(sid int64, translation dao.Translation) []model.Translation {
+	records := make([]model.Translation, 0)
+	add := func(key translationKey, content, description string) {
+		if content == "" && description == "" {
+			return
+		}
+		records = append(records, model.Translation{
+			SurveyID:     sid,
+			Locale:       translation.Locale,
+			QuestionKey:  key.question,
+			OptionSerial: key.serial,
+			IsColumn:     key.column,
+			Content:      content,
+			Description:  description,
+		})
+	}
+	add(translationKey{}, translation.Title, translation.Desc)
+	for _, question := range translation.Questions {
+		add(translationKey{question: question.Key}, question.Subject, question.Description)
+		for _, option := range question.Options {
+			add(translationKey{question.Key, option.SerialNum, false}, option.Content, option.Description)
+		}
+		for _, column := range question.Columns {
+			add(translationKey{question.Key, column.SerialNum, true}, column.Content, column.Description)
+		}
+	}
+	return records
+}
+
+// GetTranslations 获取问卷各语言的翻译
+func GetTranslations(sid int64) ([]dao.Translation, error) {
+	records, err := d.GetTranslationsBySurveyID(ctx, sid)
+	if err != nil {
+		return nil, err
+	}
+	return buildTranslations(records), nil
+}
+
+// GetTranslation 获取问卷某一语言的翻译
+func GetTranslation(sid int64, locale string) (dao.Translation, error) {
+	records, err := d.GetTranslationsByLocale(ctx, sid, locale)
+	if err != nil {
+		return dao.Translation{}, err
+	}
+	translations := buildTranslations(records)
+	if len(translations) == 0 {
+		return dao.Translation{Locale: locale, Questions: make([]dao.QuestionTranslation, 0)}, nil
+	}
+	return translations[0], nil
+}
+
+// buildTranslations 将翻译记录按语言和题目组合为翻译
+func buildTranslations(records []model.Translation) []dao.Translation {
+	sort.SliceStable(records, func(i, j int) bool {
+		if records[i].Locale != records[j].Locale {
+			return records[i].Locale < records[j].Locale
+		}
+		return records[i].OptionSerial < records[j].OptionSerial
+	})
+	translations := make([]dao.Translation, 0)
+	localeIndex := make(map[string]int)
+	questionIndex := make(map[string]map[string]int)
+	for _, record := range records {
+		li, ok := localeIndex[record.Locale]
+		if !ok {
+			li = len(translations)
+			localeIndex[record.Locale] = li
+			questionIndex[record.Locale] = make(map[string]int)
+			translations = append(translations, dao.Translation{
+				Locale:    record.Locale,
+				Questions: make([]dao.QuestionTranslation, 0),
+			})
+		}
+		translation := &translations[li]
+		if record.QuestionKey == "" {
+			translation.Title = record.Content
+			translation.Desc = record.Description
+			continue
+		}
+		qi, ok := questionIndex[record.Locale][record.QuestionKey]
+		if !ok {
+			qi = len(translation.Questions)
+			questionIndex[record.Locale][record.QuestionKey] = qi
+			translation.Questions = append(translation.Questions, dao.QuestionTranslation{
+				Key:     record.QuestionKey,
+				Options: make([]dao.OptionTranslation, 0),
+				Columns: make([]dao.OptionTranslation, 0),
+			})
+		}
+		question := &translation.Questions[qi]
+		option := dao.OptionTranslation{
+			SerialNum:   record.OptionSerial,
+			Content:     record.Content,
+			Description: record.Description,
+		}
+		switch {
+		case record.OptionSerial == 0:
+			question.Subject = record.Content
+			question.Description = record.Description
+		case record.IsColumn:
+			question.Columns = append(question.Columns, option)
+		default:
+			question.Options = append(question.Options, option)
+		}
+	}
+	return translations
+}
+
+// saveTranslations 保存问卷各语言的翻译
+func saveTranslations(db dao.Daos, sid int64, translations []dao.Translation) error {
+	records := make([]model.Translation, 0)
+	for _, translation := range translations {
+		if translation.Locale == "" {
+			continue
+		}
+		records = append(records, getTranslationRecords(sid, translation)...)
+	}
+	return db.CreateTranslations(ctx, records)
+}
+
+// GetTranslationLocales 获取问卷已有翻译的语言
+func GetTranslationLocales(sid int64) ([]string, error) {
+	return d.GetTranslationLocales(ctx, sid)
+}
+
+// DeleteTranslation 删除问卷某一语言的翻译
+func DeleteTranslation(sid int64, locale string) error {
+	return d.DeleteTranslationsByLocale(ctx, sid, locale)
+}
+
+// NormalizeAnswers 将选择题中按译文提交的选项内容还原为原文
+// 答卷统一保存原文，统计仍按选项序号汇总，各语言的答卷可以合并统计
+func NormalizeAnswers(sid int64, questionsList []dao.QuestionsList) ([]dao.QuestionsList, error) {
+	records, err := d.GetTranslationsBySurveyID(ctx, sid)
+	if err != nil || len(records) == 0 {
+		return questionsList, err
+	}
+	// 题目标识和选项序号对应的各语言译文
+	translated := make(map[string]map[int][]string)
+	for _, record := range records {
+		if record.QuestionKey == "" || record.OptionSerial == 0 || record.IsColumn || record.Content == "" {
+			continue
+		}
+		if translated[record.QuestionKey] == nil {
+			translated[record.QuestionKey] = make(map[int][]string)
+		}
+		translated[record.QuestionKey][record.OptionSerial] = append(
+			translated[record.QuestionKey][record.OptionSerial], record.Content)
+	}
+	questions, err := d.GetQuestionsBySurveyID(ctx, sid)
+	if err != nil {
+		return nil, err
+	}
+	contentMaps := make(map[int]map[string]string)
+	for _, question := range questions {
+		if question.QuestionType != 1 && question.QuestionType != 2 {
+			continue
+		}
+		serials := translated[QuestionKey(question)]
+		if len(serials) == 0 {
+			continue
+		}
+		options, err := d.GetOptionsByQuestionID(ctx, question.ID)
+		if err != nil {
+			return nil, err
+		}
+		contentMap := make(map[string]string)
+		for _, option := range options {
+			for _, content := range serials[option.SerialNum] {
+				contentMap[content] = option.Content
+			}
+		}
+		// 原文优先，避免译文与其他选项的原文相同时被改写
+		for _, option := range options {
+			contentMap[option.Content] = option.Content
+		}
+		contentMaps[question.ID] = contentMap
+	}
+	for i, q := range questionsList {
+		contentMap, ok := contentMaps[q.QuestionID]
+		if !ok || q.Answer == "" {
+			continue
+		}
+		answers := strings.Split(q.Answer, "┋")
+		for j, answer := range answers {
+			if content, ok := contentMap[answer]; ok {
+				answers[j] = content
+			}
+		}
+		questionsList[i].Answer = strings.Join(answers, "┋")
+	}
+	return questionsList, nil
+}
@@ -0,0 +1,34 @@
+package service
+
+import "testing"
+
+func TestMatchLocale(t *testing.T) {
+	locales := []string{"en", "ja-JP"}
+	tests := []struct {
+		name           string
+		locales        []string
+		lang           string
+		acceptLanguage string
+		want           string
+	}{
+		{"没有翻译", nil, "en", "en", ""},
+		{"请求参数优先", locales, "ja-JP", "en", "ja-JP"},
+		{"请求参数没有翻译时使用请求头", locales, "fr", "en", "en"},
+		{"按主语言匹配", locales, "", "en-US", "en"},
+		{"忽略大小写和下划线", locales, "", "JA_jp", "ja-JP"},
+		{"按权重选择", locales, "", "en;q=0.5, ja;q=0.8", "ja-JP"},
+		{"权重相同保持原顺序", locales, "", "ja;q=0.7, en;q=0.7", "ja-JP"},
+		{"缺省权重为1", locales, "", "en;q=0.9, ja", "ja-JP"},
+		{"跳过权重为0的语言", locales, "", "ja;q=0, en;q=0.1", "en"},
+		{"忽略权重之外的参数", locales, "", "ja;level=1;q=0.2, en;q=0.1", "ja-JP"},
+		{"没有匹配的翻译时使用原文", locales, "", "zh-CN, zh;q=0.9", ""},
+		{"通配符不匹配", locales, "", "*", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MatchLocale(tt.locales, tt.lang, tt.acceptLanguage); got != tt.want {
+				t.Errorf("MatchLocale(%q, %q) = %q, want %q", tt.lang, tt.acceptLanguage, got, tt.want)
+			}
+		})
+	}
+}
@@ -11,6 +11,8 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/zjutjh/WeJH-SDK/oauth"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
 	_ "golang.org/x/image/bmp" // 注册解码器
 	_ "golang.org/x/image/tiff"
 	_ "golang.org/x/image/webp"
@@ -44,18 +46,97 @@ func GetQuestionByID(id int) (*model.Question, error) {
 }
 
 // SubmitSurvey 提交问卷
-func SubmitSurvey(sid int64, data []dao.QuestionsList, t string) error {
+func SubmitSurvey(sid int64, data []dao.QuestionsList, t string) (*dao.AnswerSheet, error) {
+	answerSheet, qids, err := buildAnswerSheet(sid, data, t)
+	if err != nil {
+		return nil, err
+	}
+	answerSheet.AnswerID = primitive.NewObjectID()
+	demoted, err := d.SaveAnswerSheet(ctx, answerSheet, qids)
+	if err != nil {
+		return nil, err
+	}
+	err = d.IncreaseSurveyNum(ctx, sid)
+	if err != nil {
+		return nil, err
+	}
+	// 推送投票实时结果，不再唯一的答卷不再计入统计
+	go func() {
+		publishLiveResult(sid, nil, answerSheet.Answers, 1)
+		publishUniqueChanges(sid, demoted, nil)
+	}()
+	err = FromSurveyIDToMsg(sid)
+	return &answerSheet, err
+}
+
+// GetAnswerSheetByStudentID 根据学号获取答题者最近一次提交的答卷
+func GetAnswerSheetByStudentID(sid int64, studentID string) (*dao.AnswerSheet, error) {
+	record, err := d.GetRecordSheetByStudentID(ctx, sid, studentID)
+	if err != nil {
+		return nil, err
+	}
+	if record.AnswerID.IsZero() {
+		return nil, mongo.ErrNoDocuments
+	}
+	return d.GetAnswerSheetByAnswerID(ctx, record.AnswerID)
+}
+
+// UpdateAnswerSheet 修改答卷，不影响填写次数和问卷填写数量
+// 原答卷占用的选项名额会换成新答卷的选项，新选项已满时返回 ErrOptionFull 以及对应的问题
+func UpdateAnswerSheet(oldSheet *dao.AnswerSheet, data []dao.QuestionsList,
+	t string) (*dao.AnswerSheet, *model.Question, error) {
+	answerSheet, qids, err := buildAnswerSheet(oldSheet.SurveyID, data, t)
+	if err != nil {
+		return nil, nil, err
+	}
+	answerSheet.AnswerID = oldSheet.AnswerID
+	// 先释放原答卷的名额再占用新答卷的名额，失败时恢复
+	oldKeys := answerSheetCapacityKeys(oldSheet)
+	if err := releaseCounters(oldKeys); err != nil {
+		return nil, nil, err
+	}
+	newKeys, fullQuestion, err := ReserveOptionCapacity(oldSheet.SurveyID, data)
+	if err != nil {
+		if restoreErr := restoreCounters(oldKeys); restoreErr != nil {
+			zap.L().Error("恢复选项名额失败", zap.Int64("survey_id", oldSheet.SurveyID), zap.Error(restoreErr))
+		}
+		return nil, fullQuestion, err
+	}
+	demoted, promoted, err := d.UpdateAnswerSheet(ctx, answerSheet, oldSheet.Answers, qids)
+	if err != nil {
+		if releaseErr := releaseCounters(newKeys); releaseErr != nil {
+			zap.L().Error("释放选项名额失败", zap.Int64("survey_id", oldSheet.SurveyID), zap.Error(releaseErr))
+		}
+		if restoreErr := restoreCounters(oldKeys); restoreErr != nil {
+			zap.L().Error("恢复选项名额失败", zap.Int64("survey_id", oldSheet.SurveyID), zap.Error(restoreErr))
+		}
+		return nil, nil, err
+	}
+	// 修改后的答卷总是唯一的，原本不唯一时相当于新增一份计入统计的答卷
+	go func() {
+		if oldSheet.Unique {
+			publishLiveResult(oldSheet.SurveyID, oldSheet.Answers, answerSheet.Answers, 0)
+		} else {
+			publishLiveResult(oldSheet.SurveyID, nil, answerSheet.Answers, 1)
+		}
+		publishUniqueChanges(oldSheet.SurveyID, demoted, promoted)
+	}()
+	return &answerSheet, nil, nil
+}
+
+// buildAnswerSheet 根据提交的答案构建答卷，测验自动评分
+// 返回答卷以及需要保证唯一的问题ID
+func buildAnswerSheet(sid int64, data []dao.QuestionsList, t string) (dao.AnswerSheet, []int, error) {
 	var answerSheet dao.AnswerSheet
 	answerSheet.SurveyID = sid
 	answerSheet.Time = t
 	answerSheet.Unique = true
-	answerSheet.AnswerID = primitive.NewObjectID()
 	qids := make([]int, 0)
 	for _, q := range data {
 		var answer dao.Answer
 		question, err := d.GetQuestionByID(ctx, q.QuestionID)
 		if err != nil {
-			return err
+			return answerSheet, nil, err
 		}
 		if question.QuestionType == 3 && question.Unique {
 			qids = append(qids, q.QuestionID)
@@ -64,20 +145,23 @@ func SubmitSurvey(sid int64, data []dao.QuestionsList, t string) error {
 		answer.Content = q.Answer
 		answerSheet.Answers = append(answerSheet.Answers, answer)
 	}
-	err := d.SaveAnswerSheet(ctx, answerSheet, qids)
+	survey, err := d.GetSurveyByID(ctx, sid)
 	if err != nil {
-		return err
+		return answerSheet, nil, err
 	}
-	err = d.IncreaseSurveyNum(ctx, sid)
-	if err != nil {
-		return err
+	answerSheet.Version = survey.Version
+	// 测验自动评分
+	if survey.Type == 2 {
+		err = gradeAnswerSheet(&answerSheet)
+		if err != nil {
+			return answerSheet, nil, err
+		}
 	}
-	err = FromSurveyIDToMsg(sid)
-	return err
+	return answerSheet, qids, nil
 }
 
 // CreateOauthRecord 创建一条统一验证记录
-func CreateOauthRecord(userInfo oauth.UserInfo, t time.Time, sid int64) error {
+func CreateOauthRecord(userInfo oauth.UserInfo, t time.Time, sid int64, answerID primitive.ObjectID) error {
 	sheet := dao.RecordSheet{
 		College:      userInfo.College,
 		Name:         userInfo.Name,
@@ -86,6 +170,7 @@ func CreateOauthRecord(userInfo oauth.UserInfo, t time.Time, sid int64) error {
 		UserTypeDesc: userInfo.UserTypeDesc,
 		Gender:       userInfo.Gender,
 		Time:         t,
+		AnswerID:     answerID,
 	}
 	return d.SaveRecordSheet(ctx, sheet, sid)
 }
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"errors"
+	"strconv"
+
+	"QA-System/internal/dao"
+	"QA-System/internal/model"
+
+	"github.com/yitter/idgenerator-go/idgen"
+	"gorm.io/gorm"
+)
+
+// QuestionKey 获取题目标识，早期创建的题目没有标识时以题目ID代替
+func QuestionKey(question model.Question) string {
+	if question.Key != "" {
+		return question.Key
+	}
+	return "q" + strconv.Itoa(question.ID)
+}
+
+// ErrQuestionKeyMismatch 修改问卷产生新版本时，提交的题目标识与当前版本的题目均不对应
+var ErrQuestionKeyMismatch = errors.New("题目标识与当前版本的题目均不对应，修改问卷时需保留原题目的标识")
+
+// checkVersionKeys 检查新版本的题目至少有一道沿用当前版本的题目标识
+// 客户端未回传题目标识时会为每道题生成新标识，历史答卷、翻译和选项名额将无法对应到新版本
+func checkVersionKeys(current []model.Question, questionList []dao.QuestionList) error {
+	if len(current) == 0 {
+		return nil
+	}
+	keys := make(map[string]bool, len(current))
+	for _, question := range current {
+		keys[QuestionKey(question)] = true
+	}
+	for _, question := range questionList {
+		if keys[question.Key] {
+			return nil
+		}
+	}
+	return ErrQuestionKeyMismatch
+}
+
+// newQuestionKey 生成新的题目标识
+func newQuestionKey() string {
+	return strconv.FormatInt(idgen.NextId(), 10)
+}
+
+// MapAnswerSheets 将历史版本答卷中的答案按题目标识对应到问卷当前版本的题目
+// 当前版本中已删除的题目保留原题目ID
+func MapAnswerSheets(sid int64, answerSheets []dao.AnswerSheet) ([]dao.AnswerSheet, error) {
+	questions, err := d.GetQuestionsBySurveyID(ctx, sid)
+	if err != nil {
+		return nil, err
+	}
+	return mapAnswerSheets(questions, answerSheets)
+}
+
+func mapAnswerSheets(questions []model.Question, answerSheets []dao.AnswerSheet) ([]dao.AnswerSheet, error) {
+	current := make(map[int]bool)
+	keyMap := make(map[string]int)
+	for _, question := range questions {
+		current[question.ID] = true
+		keyMap[QuestionKey(question)] = question.ID
+	}
+	// 历史题目ID到当前题目ID的映射，0 表示当前版本中没有对应题目
+	mapped := make(map[int]int)
+	for i := range answerSheets {
+		answers := make([]dao.Answer, 0, len(answerSheets[i].Answers))
+		for _, answer := range answerSheets[i].Answers {
+			if !current[answer.QuestionID] {
+				id, ok := mapped[answer.QuestionID]
+				if !ok {
+					question, err := d.GetQuestionByID(ctx, answer.QuestionID)
+					if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+						return nil, err
+					}
+					if err == nil {
+						id = keyMap[QuestionKey(*question)]
+					}
+					mapped[answer.QuestionID] = id
+				}
+				if id != 0 {
+					answer.QuestionID = id
+				}
+			}
+			answers = append(answers, answer)
+		}
+		answerSheets[i].Answers = answers
+	}
+	return answerSheets, nil
+}
+
+// buildQuestionAnswers 按题目汇总答卷，每份答卷在各题下各占一项，未作答为空
+// 当前版本中已删除的题目在最后单独成列
+func buildQuestionAnswers(questions []model.Question, answerSheets []dao.AnswerSheet) ([]dao.QuestionAnswers, error) {
+	data := make([]dao.QuestionAnswers, 0, len(questions))
+	columns := make(map[int]int)
+	addColumn := func(question model.Question) {
+		columns[question.ID] = len(data)
+		data = append(data, dao.QuestionAnswers{
+			Title:        question.Subject,
+			QuestionType: question.QuestionType,
+			Answers:      make([]string, 0, len(answerSheets)),
+		})
+	}
+	for _, question := range questions {
+		addColumn(question)
+	}
+	for _, answerSheet := range answerSheets {
+		for _, answer := range answerSheet.Answers {
+			if _, ok := columns[answer.QuestionID]; ok {
+				continue
+			}
+			question, err := d.GetQuestionByID(ctx, answer.QuestionID)
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			addColumn(*question)
+		}
+	}
+	for _, answerSheet := range answerSheets {
+		row := make([]string, len(data))
+		for _, answer := range answerSheet.Answers {
+			index, ok := columns[answer.QuestionID]
+			if !ok {
+				continue
+			}
+			content := answer.Content
+			if data[index].QuestionType == 7 {
+				content = formatMatrixAnswer(answer.QuestionID, content)
+			}
+			row[index] = content
+		}
+		for i := range data {
+			data[i].Answers = append(data[i].Answers, row[i])
+		}
+	}
+	return data, nil
+}
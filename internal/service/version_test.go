@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"QA-System/internal/dao"
+	"QA-System/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// questionDaos 只提供按ID获取题目的数据访问对象，其余方法未实现
+type questionDaos struct {
+	dao.Daos
+	questions map[int]model.Question
+}
+
+func (q *questionDaos) GetQuestionByID(_ context.Context, questionID int) (*model.Question, error) {
+	question, ok := q.questions[questionID]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &question, nil
+}
+
+func TestMapAnswerSheets(t *testing.T) {
+	// 第1版：题目1(a)、2(b)、3(c)；第2版：题目11(b)、12(a)、13(d)，题目c已删除
+	history := map[int]model.Question{
+		1: {ID: 1, Key: "a", Version: 1},
+		2: {ID: 2, Key: "b", Version: 1},
+		3: {ID: 3, Key: "c", Version: 1},
+		4: {ID: 4, Version: 1},
+	}
+	current := []model.Question{
+		{ID: 11, Key: "b", Version: 2},
+		{ID: 12, Key: "a", Version: 2},
+		{ID: 13, Key: "d", Version: 2},
+	}
+	original := d
+	d = &questionDaos{questions: history}
+	defer func() { d = original }()
+
+	tests := []struct {
+		name    string
+		answers []dao.Answer
+		want    []dao.Answer
+	}{
+		{
+			name:    "当前版本的答案不变",
+			answers: []dao.Answer{{QuestionID: 11, Content: "x"}, {QuestionID: 13, Content: "y"}},
+			want:    []dao.Answer{{QuestionID: 11, Content: "x"}, {QuestionID: 13, Content: "y"}},
+		},
+		{
+			name:    "历史版本按题目标识对应到调整顺序后的题目",
+			answers: []dao.Answer{{QuestionID: 1, Content: "x"}, {QuestionID: 2, Content: "y"}},
+			want:    []dao.Answer{{QuestionID: 12, Content: "x"}, {QuestionID: 11, Content: "y"}},
+		},
+		{
+			name:    "已删除的题目保留原题目ID",
+			answers: []dao.Answer{{QuestionID: 3, Content: "x"}, {QuestionID: 4, Content: "y"}},
+			want:    []dao.Answer{{QuestionID: 3, Content: "x"}, {QuestionID: 4, Content: "y"}},
+		},
+		{
+			name:    "不存在的题目保留原题目ID",
+			answers: []dao.Answer{{QuestionID: 99, Content: "x"}},
+			want:    []dao.Answer{{QuestionID: 99, Content: "x"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sheets, err := mapAnswerSheets(current, []dao.AnswerSheet{{Answers: tt.answers}})
+			if err != nil {
+				t.Fatalf("mapAnswerSheets() error = %v", err)
+			}
+			if !reflect.DeepEqual(sheets[0].Answers, tt.want) {
+				t.Errorf("mapAnswerSheets() = %+v, want %+v", sheets[0].Answers, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuestionKey(t *testing.T) {
+	tests := []struct {
+		question model.Question
+		want     string
+	}{
+		{model.Question{ID: 5, Key: "abc"}, "abc"},
+		{model.Question{ID: 5}, "q5"},
+	}
+	for _, tt := range tests {
+		if got := QuestionKey(tt.question); got != tt.want {
+			t.Errorf("QuestionKey(%+v) = %q, want %q", tt.question, got, tt.want)
+		}
+	}
+}
+
+func TestCheckVersionKeys(t *testing.T) {
+	current := []model.Question{{ID: 1, Key: "a"}, {ID: 2}}
+	tests := []struct {
+		name         string
+		current      []model.Question
+		questionList []dao.QuestionList
+		want         error
+	}{
+		{"当前版本没有题目", nil, []dao.QuestionList{{}}, nil},
+		{"沿用题目标识", current, []dao.QuestionList{{Key: "a"}, {}}, nil},
+		{"沿用以题目ID代替的标识", current, []dao.QuestionList{{Key: "q2"}}, nil},
+		{"未回传题目标识", current, []dao.QuestionList{{}, {}}, ErrQuestionKeyMismatch},
+		{"题目标识均不对应", current, []dao.QuestionList{{Key: "b"}}, ErrQuestionKeyMismatch},
+		{"删除全部题目", current, nil, ErrQuestionKeyMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkVersionKeys(tt.current, tt.questionList); err != tt.want {
+				t.Errorf("checkVersionKeys() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"QA-System/internal/model"
+	"QA-System/internal/pkg/utils"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// 投票结果公开范围
+const (
+	ResultVisibleAlways        uint = iota // 总是公开
+	ResultVisibleNever                     // 不公开
+	ResultVisibleAfterSubmit               // 提交后公开
+	ResultVisibleAfterDeadline             // 截止后公开
+	ResultVisibleVerified                  // 仅统一验证用户可见
+)
+
+// ErrResultsHidden 投票结果对当前查看者不公开
+var ErrResultsHidden = errors.New("投票结果暂不公开")
+
+// ErrLiveResultsHidden 投票截止前不公布实时结果
+var ErrLiveResultsHidden = errors.New("投票截止前不公布实时结果")
+
+// CheckResultVisibility 根据问卷的结果公开范围检查查看者能否查看投票结果
+// 提交后公开和仅统一验证用户可见需要通过 token 确认查看者身份，提交后公开还要求查看者已提交答卷
+// 问卷开启截止前隐藏实时结果时，截止前返回 ErrLiveResultsHidden
+func CheckResultVisibility(survey *model.Survey, token string) error {
+	if survey.HideLiveResults && time.Now().Before(survey.Deadline) {
+		return ErrLiveResultsHidden
+	}
+	switch survey.ResultVisibility {
+	case ResultVisibleNever:
+		return ErrResultsHidden
+	case ResultVisibleAfterDeadline:
+		if time.Now().Before(survey.Deadline) {
+			return ErrResultsHidden
+		}
+	case ResultVisibleAfterSubmit, ResultVisibleVerified:
+		userInfo, err := utils.ParseJWT(token)
+		if err != nil {
+			return ErrResultsHidden
+		}
+		if survey.ResultVisibility == ResultVisibleVerified {
+			return nil
+		}
+		_, err = d.GetRecordSheetByStudentID(ctx, survey.ID, userInfo.StudentID)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrResultsHidden
+		}
+		return err
+	}
+	return nil
+}
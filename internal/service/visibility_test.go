@@ -0,0 +1,36 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"QA-System/internal/model"
+)
+
+func TestCheckResultVisibility(t *testing.T) {
+	future := time.Now().Add(time.Hour)
+	past := time.Now().Add(-time.Hour)
+	tests := []struct {
+		name   string
+		survey model.Survey
+		want   error
+	}{
+		{"总是公开", model.Survey{ResultVisibility: ResultVisibleAlways, Deadline: future}, nil},
+		{"不公开", model.Survey{ResultVisibility: ResultVisibleNever, Deadline: past}, ErrResultsHidden},
+		{"截止后公开但未截止", model.Survey{ResultVisibility: ResultVisibleAfterDeadline, Deadline: future},
+			ErrResultsHidden},
+		{"截止后公开且已截止", model.Survey{ResultVisibility: ResultVisibleAfterDeadline, Deadline: past}, nil},
+		{"截止前隐藏实时结果", model.Survey{HideLiveResults: true, Deadline: future}, ErrLiveResultsHidden},
+		{"截止后不再隐藏实时结果", model.Survey{HideLiveResults: true, Deadline: past}, nil},
+		{"统一验证用户可见但 token 无效", model.Survey{ResultVisibility: ResultVisibleVerified, Deadline: future},
+			ErrResultsHidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := CheckResultVisibility(&tt.survey, "invalid"); !errors.Is(err, tt.want) {
+				t.Errorf("CheckResultVisibility() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -74,6 +74,9 @@ func main() {
 		zap.L().Error("Error executing plugins", zap.Error(err))
 	}
 
+	// 启动问卷定时发布与截止任务
+	service.StartScheduler()
+
 	// 初始化gin
 	r := gin.Default()
 	r.Use(middleware.ErrHandler())
@@ -14,6 +14,11 @@ type Plugin interface {
 	Execute(params map[string]any) error // Execute 执行插件功能，接收参数
 }
 
+// PluginEventHandler 定义插件事件处理接口，实现该接口的插件会收到系统事件
+type PluginEventHandler interface {
+	HandleEvent(event string, params map[string]any) error // HandleEvent 处理系统事件
+}
+
 // PluginHealthChecker 定义插件健康检查接口
 type PluginHealthChecker interface {
 	IsHealthy() bool   // IsHealthy 检查插件是否健康可用
@@ -154,6 +154,34 @@ func (pm *PluginManager) ExecutePluginList() error {
 	return nil
 }
 
+// DispatchEvent 将系统事件分发给所有实现了事件处理接口的插件，插件失败不会中断分发
+func (pm *PluginManager) DispatchEvent(event string, params map[string]any) {
+	pm.mu.Lock()
+	handlers := make([]Plugin, 0, len(pm.plugins))
+	for _, p := range pm.plugins {
+		if _, ok := p.(PluginEventHandler); ok {
+			handlers = append(handlers, p)
+		}
+	}
+	pm.mu.Unlock()
+
+	for _, p := range handlers {
+		metadata := p.GetMetadata()
+		if healthChecker, ok := p.(PluginHealthChecker); ok && !healthChecker.IsHealthy() {
+			pm.logger.Warn("Plugin is unhealthy, skipping event",
+				"name", metadata.Name,
+				"event", event)
+			continue
+		}
+		if err := p.(PluginEventHandler).HandleEvent(event, params); err != nil {
+			pm.logger.Warn("Plugin failed to handle event, but continuing",
+				"name", metadata.Name,
+				"event", event,
+				"error", err)
+		}
+	}
+}
+
 // GetPlugin 从已经注册到插件管理器中的插件集合里获取特定的插件实例
 func (pm *PluginManager) GetPlugin(name string) (Plugin, bool) {
 	pm.mu.Lock()
@@ -192,6 +220,11 @@ func ExecutePluginSafely(name string, params map[string]any) {
 	GetDefaultManager().ExecutePluginSafely(name, params)
 }
 
+// DispatchEvent （包级）向默认插件管理器中的插件分发系统事件
+func DispatchEvent(event string, params map[string]any) {
+	GetDefaultManager().DispatchEvent(event, params)
+}
+
 // GetPluginStatus （包级）获取插件状态信息
 func GetPluginStatus(name string) (string, bool) {
 	plugin, ok := GetDefaultManager().GetPlugin(name)